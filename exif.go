@@ -0,0 +1,278 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"io"
+	"os"
+)
+
+// readJPEGOrientation re-opens path and scans its JPEG markers for an APP1
+// segment carrying an EXIF Orientation tag, returning the orientation value
+// (1-8, where 1 means "no correction needed"). It returns an error for
+// non-JPEG files or files with no orientation tag, in which case callers
+// should leave the image as decoded.
+func readJPEGOrientation(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 1, err
+	}
+	defer file.Close()
+
+	var soi [2]byte
+	if _, err := io.ReadFull(file, soi[:]); err != nil {
+		return 1, err
+	}
+	if soi[0] != 0xFF || soi[1] != 0xD8 {
+		return 1, fmt.Errorf("not a JPEG file: %s", path)
+	}
+
+	for {
+		var marker [2]byte
+		if _, err := io.ReadFull(file, marker[:]); err != nil {
+			return 1, err
+		}
+		if marker[0] != 0xFF {
+			return 1, fmt.Errorf("malformed JPEG marker in %s", path)
+		}
+		// Markers with no payload: TEM/RSTn and the lone 0xFF fill byte.
+		if marker[1] == 0x01 || (marker[1] >= 0xD0 && marker[1] <= 0xD7) {
+			continue
+		}
+		if marker[1] == 0xD9 || marker[1] == 0xDA {
+			break // EOI or start-of-scan: no more metadata markers follow
+		}
+
+		var lenBuf [2]byte
+		if _, err := io.ReadFull(file, lenBuf[:]); err != nil {
+			return 1, err
+		}
+		segLen := int(binary.BigEndian.Uint16(lenBuf[:]))
+		if segLen < 2 {
+			return 1, fmt.Errorf("malformed JPEG segment length in %s", path)
+		}
+		payload := make([]byte, segLen-2)
+		if _, err := io.ReadFull(file, payload); err != nil {
+			return 1, err
+		}
+
+		if marker[1] == 0xE1 { // APP1
+			if orientation, ok := parseExifOrientation(payload); ok {
+				return orientation, nil
+			}
+		}
+	}
+
+	return 1, fmt.Errorf("no EXIF orientation tag in %s", path)
+}
+
+// parseExifOrientation reads the Orientation tag (0x0112) out of an APP1
+// payload's embedded TIFF structure, returning ok=false if the payload
+// isn't EXIF or doesn't carry that tag.
+func parseExifOrientation(payload []byte) (int, bool) {
+	if len(payload) < 10 || string(payload[0:6]) != "Exif\x00\x00" {
+		return 0, false
+	}
+	tiff := payload[6:]
+	if len(tiff) < 8 {
+		return 0, false
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[0:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0, false
+	}
+
+	return orientationFromIFD(tiff, order, order.Uint32(tiff[4:8]))
+}
+
+// orientationFromIFD scans the IFD at ifdOffset within data (encoded with
+// order) for a SHORT-valued Orientation tag (0x0112), returning ok=false if
+// it's absent, malformed, or out of the valid 1-8 range. Shared by
+// parseExifOrientation (an embedded TIFF inside a JPEG's APP1 segment) and
+// readTIFFOrientation (a standalone TIFF file's own IFD).
+func orientationFromIFD(data []byte, order binary.ByteOrder, ifdOffset uint32) (int, bool) {
+	if int(ifdOffset)+2 > len(data) {
+		return 0, false
+	}
+
+	entryCount := order.Uint16(data[ifdOffset : ifdOffset+2])
+	base := int(ifdOffset) + 2
+	for i := 0; i < int(entryCount); i++ {
+		entryOff := base + i*12
+		if entryOff+12 > len(data) {
+			break
+		}
+		tag := order.Uint16(data[entryOff : entryOff+2])
+		if tag != 0x0112 {
+			continue
+		}
+		valueType := order.Uint16(data[entryOff+2 : entryOff+4])
+		if valueType != 3 { // SHORT
+			return 0, false
+		}
+		value := int(order.Uint16(data[entryOff+8 : entryOff+10]))
+		if value < 1 || value > 8 {
+			return 0, false
+		}
+		return value, true
+	}
+
+	return 0, false
+}
+
+// readTIFFOrientation reads the Orientation tag (0x0112) directly from a
+// standalone TIFF file's own IFD, honoring --page the same way loadTIFFPage
+// does, so a multi-page TIFF is oriented using the page actually being
+// converted. It returns an error (orientation 1, "no correction needed")
+// for non-TIFF files or files with no orientation tag.
+func readTIFFOrientation(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 1, err
+	}
+	if len(data) < 8 {
+		return 1, fmt.Errorf("not a valid TIFF file: %s", path)
+	}
+
+	var order binary.ByteOrder
+	switch string(data[0:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1, fmt.Errorf("not a valid TIFF file: %s", path)
+	}
+
+	ifdOffset := order.Uint32(data[4:8])
+	for i := 0; i < pageNum; i++ {
+		next, nerr := nextTIFFIFDOffset(data, order, ifdOffset)
+		if nerr != nil || next == 0 {
+			return 1, fmt.Errorf("TIFF page %d out of range: %s", pageNum, path)
+		}
+		ifdOffset = next
+	}
+
+	if orientation, ok := orientationFromIFD(data, order, ifdOffset); ok {
+		return orientation, nil
+	}
+	return 1, fmt.Errorf("no TIFF orientation tag in %s", path)
+}
+
+// applyOrientation undoes the EXIF Orientation transform so the pixels
+// come out right-side up, per the standard 1-8 orientation values.
+func applyOrientation(img image.Image, orientation int) image.Image {
+	switch orientation {
+	case 2:
+		return flipH(img)
+	case 3:
+		return rotate180(img)
+	case 4:
+		return flipV(img)
+	case 5:
+		return transpose(img)
+	case 6:
+		return rotate90CW(img)
+	case 7:
+		return transverse(img)
+	case 8:
+		return rotate90CCW(img)
+	default:
+		return img
+	}
+}
+
+func flipH(img image.Image) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	out := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			out.Set(w-1-x, y, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return out
+}
+
+func flipV(img image.Image) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	out := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			out.Set(x, h-1-y, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return out
+}
+
+func rotate180(img image.Image) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	out := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			out.Set(w-1-x, h-1-y, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return out
+}
+
+// transpose mirrors across the top-left-to-bottom-right diagonal, swapping
+// width and height.
+func transpose(img image.Image) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	out := image.NewNRGBA(image.Rect(0, 0, h, w))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			out.Set(y, x, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return out
+}
+
+// transverse mirrors across the anti-diagonal (top-right to bottom-left),
+// swapping width and height.
+func transverse(img image.Image) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	out := image.NewNRGBA(image.Rect(0, 0, h, w))
+	for i := 0; i < w; i++ {
+		for j := 0; j < h; j++ {
+			out.Set(j, i, img.At(b.Min.X+w-1-i, b.Min.Y+h-1-j))
+		}
+	}
+	return out
+}
+
+func rotate90CW(img image.Image) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	out := image.NewNRGBA(image.Rect(0, 0, h, w))
+	for i := 0; i < w; i++ {
+		for j := 0; j < h; j++ {
+			out.Set(j, i, img.At(b.Min.X+i, b.Min.Y+h-1-j))
+		}
+	}
+	return out
+}
+
+func rotate90CCW(img image.Image) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	out := image.NewNRGBA(image.Rect(0, 0, h, w))
+	for i := 0; i < w; i++ {
+		for j := 0; j < h; j++ {
+			out.Set(j, i, img.At(b.Min.X+w-1-i, b.Min.Y+j))
+		}
+	}
+	return out
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/image/tiff"
+)
+
+// markerImage builds a 2x1 image with distinct colors per pixel, so
+// geometric transforms can be verified by tracking where each color ends
+// up rather than relying on symmetry.
+func markerImage() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.NRGBA{R: 255, A: 255}) // top-left: red
+	img.Set(1, 0, color.NRGBA{G: 255, A: 255}) // top-right: green
+	return img
+}
+
+func at(img image.Image, x, y int) color.NRGBA {
+	r, g, b, a := img.At(x, y).RGBA()
+	return color.NRGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: uint8(a >> 8)}
+}
+
+func TestFlipH(t *testing.T) {
+	out := flipH(markerImage())
+	if at(out, 1, 0) != (color.NRGBA{R: 255, A: 255}) {
+		t.Error("flipH should move the red pixel from x=0 to x=1")
+	}
+	if at(out, 0, 0) != (color.NRGBA{G: 255, A: 255}) {
+		t.Error("flipH should move the green pixel from x=1 to x=0")
+	}
+}
+
+func TestRotate180(t *testing.T) {
+	img := markerImage()
+	out := rotate180(img)
+	if at(out, 1, 0) != at(img, 0, 0) || at(out, 0, 0) != at(img, 1, 0) {
+		t.Error("rotate180 on a 2x1 image should swap the two pixels")
+	}
+}
+
+// taggedImage is a 2x1 image where pixel (0,0) is red and (1,0) is green,
+// used to verify rotate90CW/CCW/transpose/transverse swap width/height and
+// place each source pixel at the documented destination.
+func TestRotate90CW(t *testing.T) {
+	out := rotate90CW(markerImage())
+	b := out.Bounds()
+	if b.Dx() != 1 || b.Dy() != 2 {
+		t.Fatalf("rotate90CW should swap dimensions to 1x2, got %dx%d", b.Dx(), b.Dy())
+	}
+	// A 90-degree clockwise rotation moves the original top row (red,
+	// green) to the right column read top-to-bottom (red on top).
+	if at(out, 0, 0) != (color.NRGBA{R: 255, A: 255}) {
+		t.Errorf("rotate90CW: (0,0) = %+v, want red", at(out, 0, 0))
+	}
+	if at(out, 0, 1) != (color.NRGBA{G: 255, A: 255}) {
+		t.Errorf("rotate90CW: (0,1) = %+v, want green", at(out, 0, 1))
+	}
+}
+
+func TestRotate90CCW(t *testing.T) {
+	out := rotate90CCW(markerImage())
+	b := out.Bounds()
+	if b.Dx() != 1 || b.Dy() != 2 {
+		t.Fatalf("rotate90CCW should swap dimensions to 1x2, got %dx%d", b.Dx(), b.Dy())
+	}
+	if at(out, 0, 0) != (color.NRGBA{G: 255, A: 255}) {
+		t.Errorf("rotate90CCW: (0,0) = %+v, want green", at(out, 0, 0))
+	}
+	if at(out, 0, 1) != (color.NRGBA{R: 255, A: 255}) {
+		t.Errorf("rotate90CCW: (0,1) = %+v, want red", at(out, 0, 1))
+	}
+}
+
+func TestApplyOrientationRoundTrip(t *testing.T) {
+	img := markerImage()
+	// Orientation 6 (rotate90CW) followed by its inverse (8, rotate90CCW)
+	// should return every pixel to its original location.
+	rotated := applyOrientation(img, 6)
+	back := applyOrientation(rotated, 8)
+	if at(back, 0, 0) != at(img, 0, 0) || at(back, 1, 0) != at(img, 1, 0) {
+		t.Error("rotate90CW followed by rotate90CCW should round-trip to the original image")
+	}
+}
+
+func TestApplyOrientationIdentity(t *testing.T) {
+	img := markerImage()
+	out := applyOrientation(img, 1)
+	if out != image.Image(img) {
+		t.Error("orientation 1 should return the image unchanged")
+	}
+}
+
+// tiffWithOrientation encodes a minimal TIFF and patches an Orientation
+// (tag 0x0112, SHORT) entry into its IFD, for exercising readTIFFOrientation
+// without needing a real camera-shot fixture.
+func tiffWithOrientation(t *testing.T, orientation uint16) string {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	var buf bytes.Buffer
+	if err := tiff.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	order := binary.LittleEndian
+	ifdOffset := order.Uint32(data[4:8])
+	entryCount := order.Uint16(data[ifdOffset : ifdOffset+2])
+
+	entry := make([]byte, 12)
+	order.PutUint16(entry[0:2], 0x0112)
+	order.PutUint16(entry[2:4], 3) // SHORT
+	order.PutUint32(entry[4:8], 1)
+	order.PutUint16(entry[8:10], orientation)
+
+	base := int(ifdOffset) + 2
+	insertAt := base + int(entryCount)*12
+	nextIFDOff := data[insertAt : insertAt+4]
+
+	out := make([]byte, 0, len(data)+12)
+	out = append(out, data[:ifdOffset]...)
+	newCount := make([]byte, 2)
+	order.PutUint16(newCount, entryCount+1)
+	out = append(out, newCount...)
+	out = append(out, data[base:insertAt]...)
+	out = append(out, entry...)
+	out = append(out, nextIFDOff...)
+	out = append(out, data[insertAt+4:]...)
+
+	path := filepath.Join(t.TempDir(), "oriented.tiff")
+	if err := os.WriteFile(path, out, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadTIFFOrientation(t *testing.T) {
+	path := tiffWithOrientation(t, 6)
+	orientation, err := readTIFFOrientation(path)
+	if err != nil {
+		t.Fatalf("readTIFFOrientation: %v", err)
+	}
+	if orientation != 6 {
+		t.Errorf("got orientation %d, want 6", orientation)
+	}
+}
+
+func TestReadTIFFOrientationMissingTag(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	var buf bytes.Buffer
+	if err := tiff.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "plain.tiff")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := readTIFFOrientation(path); err == nil {
+		t.Fatal("expected an error when the TIFF has no Orientation tag")
+	}
+}
+
+func TestReadTIFFOrientationNotATIFF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notatiff.bin")
+	if err := os.WriteFile(path, []byte("not a tiff"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readTIFFOrientation(path); err == nil {
+		t.Fatal("expected an error for a non-TIFF file")
+	}
+}
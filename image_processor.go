@@ -3,17 +3,16 @@ package main
 import (
 	"fmt"
 	"image"
-	_ "image/gif"
-	_ "image/jpeg"
-	_ "image/png"
-	"math"
+	"image/color"
+	"image/draw"
 	"path/filepath"
-	"time"
+	"strings"
+	"sync"
 )
 
 type Block struct {
 	X, Y, Width, Height int
-	R, G, B, A          uint8  // Added Alpha channel
+	R, G, B, A          uint8
 }
 
 func loadAndPrepareImage(input string, width, height int) (image.Image, int64, error) {
@@ -31,13 +30,30 @@ func loadAndPrepareImage(input string, width, height int) (image.Image, int64, e
 		return nil, 0, fmt.Errorf("load image: %w", err)
 	}
 
+	if !noAutoOrient {
+		readOrientation := readJPEGOrientation
+		ext := strings.ToLower(filepath.Ext(input))
+		if ext == ".tiff" || ext == ".tif" {
+			readOrientation = readTIFFOrientation
+		}
+		if orientation, operr := readOrientation(input); operr == nil && orientation != 1 {
+			img = applyOrientation(img, orientation)
+			if !quiet {
+				fmt.Printf("Corrected EXIF orientation (tag %d)\n", orientation)
+			}
+		}
+	}
+
 	// Check if image has transparency
 	if hasTransparency(img) && !quiet {
 		fmt.Printf("Image has transparency, optimizing transparent areas...\n")
 	}
 
 	if width > 0 || height > 0 {
-		img = resizeImage(img, width, height)
+		img, err = resizeImage(img, width, height)
+		if err != nil {
+			return nil, 0, fmt.Errorf("resize error: %w", err)
+		}
 	}
 
 	bounds := img.Bounds()
@@ -53,7 +69,7 @@ func loadAndPrepareImage(input string, width, height int) (image.Image, int64, e
 // hasTransparency checks if image contains transparent pixels
 func hasTransparency(img image.Image) bool {
 	bounds := img.Bounds()
-	
+
 	// Quick check: sample some pixels for transparency
 	for y := bounds.Min.Y; y < bounds.Max.Y && y < bounds.Min.Y+100; y += 10 {
 		for x := bounds.Min.X; x < bounds.Max.X && x < bounds.Min.X+100; x += 10 {
@@ -66,42 +82,92 @@ func hasTransparency(img image.Image) bool {
 	return false
 }
 
-func loadAndValidateImage(path string) (image.Image, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, fmt.Errorf("open file: %w", err)
+// parseHexColor parses a "#rrggbb", "rrggbb", "#rgb" or "rgb" color,
+// defaulting to fully-opaque white when s is empty.
+func parseHexColor(s string) (color.RGBA, error) {
+	if s == "" {
+		return color.RGBA{R: 255, G: 255, B: 255, A: 255}, nil
 	}
-	defer file.Close()
 
-	img, format, err := image.Decode(file)
-	if err != nil {
-		return nil, fmt.Errorf("decode image: %w", err)
+	s = trimHexPrefix(s)
+	var r, g, b uint8
+	var n int
+	var err error
+	switch len(s) {
+	case 3:
+		n, err = fmt.Sscanf(s, "%1x%1x%1x", &r, &g, &b)
+		r, g, b = r*17, g*17, b*17
+	case 6:
+		n, err = fmt.Sscanf(s, "%02x%02x%02x", &r, &g, &b)
+	default:
+		return color.RGBA{}, fmt.Errorf("invalid hex color %q: expected 3 or 6 hex digits", s)
 	}
-
-	if !quiet {
-		fmt.Printf("Detected format: %s\n", format)
+	if err != nil || n != 3 {
+		return color.RGBA{}, fmt.Errorf("invalid hex color %q: %v", s, err)
 	}
+	return color.RGBA{R: r, G: g, B: b, A: 255}, nil
+}
 
-	return img, nil
+func trimHexPrefix(s string) string {
+	if len(s) > 0 && s[0] == '#' {
+		return s[1:]
+	}
+	return s
 }
 
-// ... (rest of the existing functions remain the same until createColorGrid)
+// matteImage flattens img onto a solid bg color, discarding the alpha
+// channel. Used when --preserve-alpha=false so transparent PNGs/GIFs get a
+// clean opaque background instead of emitting fill-opacity rects.
+func matteImage(img image.Image, bg color.RGBA) image.Image {
+	bounds := img.Bounds()
+	out := image.NewRGBA(bounds)
+	draw.Draw(out, bounds, &image.Uniform{C: bg}, image.Point{}, draw.Src)
+	draw.Draw(out, bounds, img, bounds.Min, draw.Over)
+	return out
+}
 
+// createColorGrid builds the per-pixel RGBA grid that findOptimalBlocks
+// operates on. Rows are split into imageWorkerCount() horizontal stripes,
+// each filled by its own goroutine, since img.At is safe for concurrent
+// reads and every goroutine owns disjoint rows of grid.
 func createColorGrid(img image.Image, width, height int, progress *ProgressTracker) [][]ColorRGBA {
 	grid := make([][]ColorRGBA, height)
-	for y := 0; y < height; y++ {
+	for y := range grid {
 		grid[y] = make([]ColorRGBA, width)
-		for x := 0; x < width; x++ {
-			r, g, b, a := img.At(x, y).RGBA()
-			grid[y][x] = ColorRGBA{
-				R: uint8(r >> 8),
-				G: uint8(g >> 8),
-				B: uint8(b >> 8),
-				A: uint8(a >> 8),
-			}
-			progress.Update(1)
+	}
+
+	workers := imageWorkerCount()
+	rowsPerWorker := (height + workers - 1) / workers
+	if rowsPerWorker < 1 {
+		rowsPerWorker = 1
+	}
+
+	var wg sync.WaitGroup
+	for start := 0; start < height; start += rowsPerWorker {
+		end := start + rowsPerWorker
+		if end > height {
+			end = height
 		}
+
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			for y := start; y < end; y++ {
+				for x := 0; x < width; x++ {
+					r, g, b, a := img.At(x, y).RGBA()
+					grid[y][x] = ColorRGBA{
+						R: uint8(r >> 8),
+						G: uint8(g >> 8),
+						B: uint8(b >> 8),
+						A: uint8(a >> 8),
+					}
+				}
+				progress.Update(width)
+			}
+		}(start, end)
 	}
+	wg.Wait()
+
 	return grid
 }
 
@@ -115,8 +181,66 @@ func (c ColorRGBA) ToUint64() uint64 {
 	return uint64(c.R)<<24 | uint64(c.G)<<16 | uint64(c.B)<<8 | uint64(c.A)
 }
 
-// findOptimalBlocks now uses ColorRGBA
+// findOptimalBlocks greedily merges same-colored pixels into the fewest
+// rectangles, treating two pixels as mergeable only if their full RGBA
+// matches. Fully-transparent pixels are skipped rather than emitted as
+// blocks, since writeBlock would drop them anyway.
+//
+// When --max-block-height caps band height below the image height, the
+// grid is split into horizontal bands, each searched independently on its
+// own goroutine (bounded by imageWorkerCount()) and concatenated back together.
+// Blocks never merge across a band boundary, which costs a little
+// compression in exchange for parallelism on large images.
 func findOptimalBlocks(grid [][]ColorRGBA, width, height int) []Block {
+	if !quiet {
+		fmt.Printf("Finding optimal blocks...\n")
+	}
+
+	if maxBlockHeight <= 0 || maxBlockHeight >= height {
+		return findBlocksInRegion(grid, width, height)
+	}
+
+	type band struct {
+		start, end int
+	}
+	var bands []band
+	for start := 0; start < height; start += maxBlockHeight {
+		end := start + maxBlockHeight
+		if end > height {
+			end = height
+		}
+		bands = append(bands, band{start, end})
+	}
+
+	results := make([][]Block, len(bands))
+	sem := make(chan struct{}, imageWorkerCount())
+	var wg sync.WaitGroup
+	for i, b := range bands {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, b band) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			blocks := findBlocksInRegion(grid[b.start:b.end], width, b.end-b.start)
+			for j := range blocks {
+				blocks[j].Y += b.start
+			}
+			results[i] = blocks
+		}(i, b)
+	}
+	wg.Wait()
+
+	var blocks []Block
+	for _, r := range results {
+		blocks = append(blocks, r...)
+	}
+	return blocks
+}
+
+// findBlocksInRegion runs the greedy rectangle merge over a single
+// contiguous grid (either the whole image or one --max-block-height band).
+func findBlocksInRegion(grid [][]ColorRGBA, width, height int) []Block {
 	used := make([][]bool, height)
 	for i := range used {
 		used[i] = make([]bool, width)
@@ -125,10 +249,6 @@ func findOptimalBlocks(grid [][]ColorRGBA, width, height int) []Block {
 	var blocks []Block
 	transparentBlocks := 0
 
-	if !quiet {
-		fmt.Printf("Finding optimal blocks...\n")
-	}
-
 	for y := 0; y < height; y++ {
 		for x := 0; x < width; x++ {
 			if used[y][x] {
@@ -136,7 +256,7 @@ func findOptimalBlocks(grid [][]ColorRGBA, width, height int) []Block {
 			}
 
 			color := grid[y][x]
-			
+
 			// Skip fully transparent pixels (optimization)
 			if color.A == 0 {
 				used[y][x] = true
@@ -173,7 +293,6 @@ func findOptimalBlocks(grid [][]ColorRGBA, width, height int) []Block {
 	return blocks
 }
 
-// Updated functions for RGBA color handling
 func findMaxWidthRGBA(grid [][]ColorRGBA, x, y int, color ColorRGBA, maxX int) int {
 	width := 1
 	for x+width < maxX && grid[y][x+width] == color {
@@ -237,27 +356,6 @@ func expandBlockRGBA(grid [][]ColorRGBA, used [][]bool, x, y, width, height int,
 	return expandedWidth, expandedHeight
 }
 
-func findMaxWidth(grid [][]uint32, x, y int, color uint32, maxX int) int {
-	width := 1
-	for x+width < maxX && grid[y][x+width] == color {
-		width++
-	}
-	return width
-}
-
-func findMaxHeight(grid [][]uint32, x, y int, color uint32, width, maxY int) int {
-	height := 1
-	for y+height < maxY {
-		for i := 0; i < width; i++ {
-			if grid[y+height][x+i] != color {
-				return height
-			}
-		}
-		height++
-	}
-	return height
-}
-
 func markBlockUsed(used [][]bool, x, y, width, height int) {
 	for i := y; i < y+height && i < len(used); i++ {
 		for j := x; j < x+width && j < len(used[i]); j++ {
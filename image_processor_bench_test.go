@@ -0,0 +1,65 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// benchGridImage builds a w x h image of 64px stripes, representative of
+// the flat-color source material this tool targets, so block-finding has
+// long runs to merge.
+func benchGridImage(w, h int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8((x / 64) % 16 * 17), G: uint8((y / 64) % 16 * 17), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+// BenchmarkCreateColorGrid3840x2160 measures createColorGrid's row-stripe
+// parallelization (via imageWorkerCount()) on a 4K fixture.
+func BenchmarkCreateColorGrid3840x2160(b *testing.B) {
+	const w, h = 3840, 2160
+	img := benchGridImage(w, h)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		createColorGrid(img, w, h, NewProgressTracker(w*h, true))
+	}
+}
+
+// BenchmarkFindOptimalBlocksSerial3840x2160 is the --max-block-height=0
+// baseline: the whole image searched as one region on a single goroutine.
+func BenchmarkFindOptimalBlocksSerial3840x2160(b *testing.B) {
+	const w, h = 3840, 2160
+	grid := createColorGrid(benchGridImage(w, h), w, h, NewProgressTracker(w*h, true))
+
+	prevMaxBlockHeight, prevQuiet := maxBlockHeight, quiet
+	maxBlockHeight, quiet = 0, true
+	defer func() { maxBlockHeight, quiet = prevMaxBlockHeight, prevQuiet }()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		findOptimalBlocks(grid, w, h)
+	}
+}
+
+// BenchmarkFindOptimalBlocksBanded3840x2160 exercises the --max-block-height
+// banded/parallel path against the same grid, to compare against the serial
+// benchmark above.
+func BenchmarkFindOptimalBlocksBanded3840x2160(b *testing.B) {
+	const w, h = 3840, 2160
+	grid := createColorGrid(benchGridImage(w, h), w, h, NewProgressTracker(w*h, true))
+
+	prevMaxBlockHeight, prevQuiet := maxBlockHeight, quiet
+	maxBlockHeight, quiet = 270, true
+	defer func() { maxBlockHeight, quiet = prevMaxBlockHeight, prevQuiet }()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		findOptimalBlocks(grid, w, h)
+	}
+}
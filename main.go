@@ -4,44 +4,55 @@ import (
 	"flag"
 	"fmt"
 	"image"
-	_ "image/gif"
+	"image/color"
+	"image/gif"
 	_ "image/jpeg"
 	_ "image/png"
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
-)
 
-type Block struct {
-	x, y, w, h int
-	r, g, b    uint8
-}
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+)
 
 var quiet bool
 var force bool
-
-// Supported image formats
-var supportedFormats = map[string]bool{
-	".jpg":  true,
-	".jpeg": true,
-	".png":  true,
-	".gif":  true,
-	".bmp":  true,
-	".tiff": true,
-	".tif":  true,
-}
+var animate bool
+var fpsOverride float64
+var recursive bool
+var jobs int
+var imageWorkers int
+var skipExisting bool
+var ignoreSuffix string
+var quantizerName string
+var svgStyle string
+var backgroundHex string
+var preserveAlpha bool
+var preprocSpec string
+var noAutoOrient bool
+var ditherEnabled bool
+var maxBlockHeight int
+var pageNum int
+var filterName string
+var smoothRadius int
+var posterizeLevels int
+var verbose bool
 
 func main() {
 	var input, output string
-	var width, height int
+	var width, height, colors int
 
-	flag.StringVar(&input, "i", "", "Input image file")
-	flag.StringVar(&input, "input", "", "Input image file")
-	flag.StringVar(&output, "o", "", "Output SVG file")
-	flag.StringVar(&output, "output", "", "Output SVG file")
+	flag.StringVar(&input, "i", "", "Input image file or directory")
+	flag.StringVar(&input, "input", "", "Input image file or directory")
+	flag.StringVar(&output, "o", "", "Output SVG file or directory")
+	flag.StringVar(&output, "output", "", "Output SVG file or directory")
 	flag.IntVar(&width, "w", 0, "Max width (0 = original)")
 	flag.IntVar(&width, "width", 0, "Max width (0 = original)")
 	flag.IntVar(&height, "h", 0, "Max height (0 = original)")
@@ -50,6 +61,27 @@ func main() {
 	flag.BoolVar(&quiet, "quiet", false, "Quiet mode (no progress bar)")
 	flag.BoolVar(&force, "f", false, "Force overwrite existing files")
 	flag.BoolVar(&force, "force", false, "Force overwrite existing files")
+	flag.BoolVar(&animate, "animate", false, "Decode multi-frame GIFs and emit an animated SVG (SMIL)")
+	flag.Float64Var(&fpsOverride, "fps-override", 0, "Override per-frame GIF delay with a fixed frame rate (0 = use GIF delays)")
+	flag.BoolVar(&recursive, "recursive", false, "When -i is a directory, descend into subdirectories")
+	flag.IntVar(&jobs, "jobs", runtime.NumCPU(), "Number of directory-mode conversions to run in parallel")
+	flag.IntVar(&imageWorkers, "image-workers", runtime.NumCPU(), "Goroutines used per image for pixel-grid/block-finding parallelism, independent of --jobs")
+	flag.BoolVar(&skipExisting, "skip-existing", false, "In directory mode, skip files whose output already exists")
+	flag.StringVar(&ignoreSuffix, "ignore-suffix", "", "In directory mode, skip input files whose name ends with this suffix")
+	flag.IntVar(&colors, "colors", 0, "Quantize to at most N palette colors before merging blocks (0 = disabled)")
+	flag.StringVar(&quantizerName, "quantizer", "median-cut", "Quantization algorithm: median-cut (the only one implemented)")
+	flag.StringVar(&svgStyle, "svg-style", "inline", "SVG color emission: inline|class|use (class/use only apply to the SVGWriter pipeline)")
+	flag.StringVar(&backgroundHex, "background", "", "Background color (hex, e.g. #ffffff) to matte onto when --preserve-alpha=false")
+	flag.BoolVar(&preserveAlpha, "preserve-alpha", true, "Keep per-pixel transparency as fill-opacity instead of matting onto --background")
+	flag.StringVar(&preprocSpec, "preproc", "", "Comma-separated preprocessing stages to run before block-finding: sauvola,otsu,median,bilateral")
+	flag.BoolVar(&noAutoOrient, "no-auto-orient", false, "Disable automatic EXIF orientation correction for JPEG/TIFF inputs")
+	flag.BoolVar(&ditherEnabled, "dither", false, "Floyd-Steinberg dither during --colors quantization (hurts block-merging, off by default)")
+	flag.IntVar(&maxBlockHeight, "max-block-height", 0, "Cap block search to bands of this many rows, searched in parallel via --image-workers (0 = unlimited, whole image)")
+	flag.IntVar(&pageNum, "page", 0, "For multi-page TIFF input, select page N (0 = first page)")
+	flag.StringVar(&filterName, "filter", filterNearest, "Resize filter: nearest|bilinear|bicubic|lanczos3 (nearest preserves flat color runs)")
+	flag.IntVar(&smoothRadius, "smooth", 0, "Box blur radius to run before --posterize, denoising JPEG noise that would break color runs (0 = disabled)")
+	flag.IntVar(&posterizeLevels, "posterize", 0, "Snap each color channel to N levels before block-finding, lengthening color runs (0 = disabled)")
+	flag.BoolVar(&verbose, "verbose", false, "Print extra detail, e.g. block counts with and without --smooth/--posterize")
 	flag.Parse()
 
 	// Support positional arguments
@@ -65,12 +97,64 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Validate input file
+	if info, err := os.Stat(input); err == nil && info.IsDir() {
+		if err := runBatch(input, output, width, height, colors); err != nil {
+			log.Fatal("Batch conversion error:", err)
+		}
+		return
+	}
+
+	opts := convertOptions{width: width, height: height, force: force, colors: colors, background: backgroundHex, preserveAlpha: preserveAlpha, preproc: preprocSpec, smooth: smoothRadius, posterize: posterizeLevels}
+	result, err := convertFile(input, output, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if !quiet {
+		printConversionSummary(result.input, result.output, result.inputSize, result.outputSize,
+			calculateSizeReduction(result.inputSize, result.outputSize), result.width, result.height, result.blocks,
+			result.paletteSize, result.uniqueColors, result.duration)
+	} else {
+		log.Printf("Converted: %s (%s) -> %s (%s) - %.1f%% reduction, %d blocks, %v",
+			filepath.Base(result.input), formatFileSize(result.inputSize),
+			filepath.Base(result.output), formatFileSize(result.outputSize),
+			calculateSizeReduction(result.inputSize, result.outputSize), result.blocks, result.duration)
+	}
+}
+
+// convertOptions carries the per-file conversion settings that both the
+// single-file path and the directory worker pool thread through convertFile.
+type convertOptions struct {
+	width, height int
+	force         bool
+	colors        int
+	background    string
+	preserveAlpha bool
+	preproc       string
+	smooth        int
+	posterize     int
+}
+
+// conversionResult summarizes one completed (or skipped) conversion, used
+// both for the single-file summary print and for the directory-mode totals.
+type conversionResult struct {
+	input, output         string
+	inputSize, outputSize int64
+	width, height, blocks int
+	paletteSize           int
+	uniqueColors          int
+	duration              time.Duration
+	skipped               bool
+}
+
+// convertFile validates, loads, converts and writes a single image. It is
+// the library function behind both single-file mode and each worker in
+// directory mode.
+func convertFile(input, output string, opts convertOptions) (*conversionResult, error) {
 	if err := validateInputFile(input); err != nil {
-		log.Fatal("Input validation error:", err)
+		return nil, fmt.Errorf("input validation error: %w", err)
 	}
 
-	// Auto-generate output filename if not provided
 	if output == "" {
 		output = autoGenerateOutputName(input)
 		if !quiet {
@@ -78,74 +162,112 @@ func main() {
 		}
 	}
 
-	// Validate output file and path
-	if err := validateOutputFile(output, force); err != nil {
-		log.Fatal("Output validation error:", err)
+	if skipExisting && fileExists(output) {
+		return &conversionResult{input: input, output: output, skipped: true}, nil
+	}
+
+	if err := validateOutputFile(output, opts.force); err != nil {
+		return nil, fmt.Errorf("output validation error: %w", err)
 	}
 
-	// Get input file size
 	inputSize, err := getFileSize(input)
 	if err != nil {
-		log.Fatal("Error getting input file size:", err)
+		return nil, fmt.Errorf("error getting input file size: %w", err)
 	}
 
 	startTime := time.Now()
-	
-	if !quiet {
-		fmt.Printf("Converting %s (%s)...\n", filepath.Base(input), formatFileSize(inputSize))
-	}
 
-	// Load and validate image
-	img, err := loadAndValidateImage(input)
-	if err != nil {
-		log.Fatal("Image loading error:", err)
+	if opts.width > 0 || opts.height > 0 {
+		if err := validateDimensions(opts.width, opts.height); err != nil {
+			return nil, fmt.Errorf("dimension validation error: %w", err)
+		}
 	}
 
-	// Validate and apply resizing
-	if width > 0 || height > 0 {
-		if err := validateDimensions(width, height); err != nil {
-			log.Fatal("Dimension validation error:", err)
+	var blocks []Block
+	var w, h, paletteSize, uniqueColors int
+
+	if animate {
+		if err := convertAnimatedGIF(input, output, opts); err != nil {
+			return nil, fmt.Errorf("animated conversion error: %w", err)
+		}
+	} else {
+		img, _, err := loadAndPrepareImage(input, opts.width, opts.height)
+		if err != nil {
+			return nil, fmt.Errorf("image loading error: %w", err)
 		}
-		img = resizeImage(img, width, height)
-	}
 
-	bounds := img.Bounds()
-	w, h := bounds.Dx(), bounds.Dy()
+		if opts.preproc != "" {
+			img, err = applyPreproc(img, opts.preproc)
+			if err != nil {
+				return nil, fmt.Errorf("preprocessing error: %w", err)
+			}
+		}
 
-	// Validate image dimensions
-	if err := validateImageDimensions(w, h); err != nil {
-		log.Fatal("Image dimension error:", err)
-	}
+		if !opts.preserveAlpha {
+			bg, err := parseHexColor(opts.background)
+			if err != nil {
+				return nil, fmt.Errorf("background color error: %w", err)
+			}
+			img = matteImage(img, bg)
+		}
+
+		var imgBeforeSmooth image.Image
+		if verbose && (opts.smooth > 0 || opts.posterize > 0) {
+			imgBeforeSmooth = img
+		}
+		if opts.smooth > 0 || opts.posterize > 0 {
+			img = applySmoothPosterize(img, opts.smooth, opts.posterize)
+		}
+
+		if opts.colors > 0 {
+			quantizer, err := NewQuantizer(quantizerName)
+			if err != nil {
+				return nil, err
+			}
+			paletted, palette := quantizer.Quantize(img, opts.colors)
+			img = paletted
+			paletteSize = len(palette)
+			uniqueColors = countUniqueColors(paletted)
+			if !quiet {
+				fmt.Printf("Quantized to %d colors (%s), %d actually used\n", paletteSize, quantizerName, uniqueColors)
+			}
+		}
 
-	// Create progress tracker
-	progress := NewProgressTracker(w*h, quiet)
-	
-	blocks := findOptimalBlocks(img, w, h, progress)
-	
-	if err := writeSVG(blocks, w, h, output, progress); err != nil {
-		log.Fatal("Error writing SVG:", err)
+		bounds := img.Bounds()
+		w, h = bounds.Dx(), bounds.Dy()
+
+		progress := NewProgressTracker(w*h, quiet)
+		grid := createColorGrid(img, w, h, progress)
+		blocks = findOptimalBlocks(grid, w, h)
+		progress.Finish()
+
+		if imgBeforeSmooth != nil && !quiet {
+			baseline := countBlocksWithout(imgBeforeSmooth, opts)
+			fmt.Printf("Blocks with --smooth/--posterize: %d, without: %d\n", len(blocks), baseline)
+		}
+
+		if err := generateSVGFile(blocks, w, h, output); err != nil {
+			return nil, fmt.Errorf("error writing SVG: %w", err)
+		}
 	}
 
-	progress.Finish()
-	
-	// Get output file size
 	outputSize, err := getFileSize(output)
 	if err != nil {
-		log.Fatal("Error getting output file size:", err)
-	}
-
-	duration := time.Since(startTime)
-	
-	if !quiet {
-		reduction := calculateSizeReduction(inputSize, outputSize)
-		printConversionSummary(input, output, inputSize, outputSize, reduction, w, h, len(blocks), duration)
-	} else {
-		reduction := calculateSizeReduction(inputSize, outputSize)
-		log.Printf("Converted: %s (%s) -> %s (%s) - %.1f%% reduction, %d blocks, %v", 
-			filepath.Base(input), formatFileSize(inputSize),
-			filepath.Base(output), formatFileSize(outputSize),
-			reduction, len(blocks), duration)
-	}
+		return nil, fmt.Errorf("error getting output file size: %w", err)
+	}
+
+	return &conversionResult{
+		input:        input,
+		output:       output,
+		inputSize:    inputSize,
+		outputSize:   outputSize,
+		width:        w,
+		height:       h,
+		blocks:       len(blocks),
+		paletteSize:  paletteSize,
+		uniqueColors: uniqueColors,
+		duration:     time.Since(startTime),
+	}, nil
 }
 
 // Print usage information
@@ -163,300 +285,456 @@ func printUsage() {
 	fmt.Println("  -h, --height   Max height (0 = original)")
 	fmt.Println("  -q, --quiet    Quiet mode")
 	fmt.Println("  -f, --force    Force overwrite existing files")
+	fmt.Println("  --animate        Decode a multi-frame GIF and emit an animated SVG")
+	fmt.Println("  --fps-override   Fixed frame rate for --animate (0 = use GIF delays)")
+	fmt.Println("  --recursive      When -i is a directory, descend into subdirectories")
+	fmt.Println("  --jobs N         Parallel conversions in directory mode (default: NumCPU)")
+	fmt.Println("  --image-workers N  Goroutines per image for grid/block-finding parallelism (default: NumCPU)")
+	fmt.Println("  --skip-existing  Skip files whose output .svg already exists")
+	fmt.Println("  --ignore-suffix  Skip input files whose name ends with this suffix")
+	fmt.Println("  --colors N       Quantize to at most N palette colors before merging blocks")
+	fmt.Println("  --quantizer      Quantization algorithm: median-cut (the only one implemented)")
+	fmt.Println("  --svg-style      SVG color emission: inline|class|use (default inline)")
+	fmt.Println("  --background     Background color (hex) to matte onto when --preserve-alpha=false")
+	fmt.Println("  --preserve-alpha Keep per-pixel transparency as fill-opacity (default true)")
+	fmt.Println("  --preproc        Comma-separated preprocessing stages: sauvola,otsu,median,bilateral")
+	fmt.Println("  --no-auto-orient Disable automatic EXIF orientation correction for JPEG/TIFF inputs")
+	fmt.Println("  --dither         Floyd-Steinberg dither during --colors quantization (off by default)")
+	fmt.Println("  --max-block-height  Cap block search to row bands, searched in parallel via --image-workers (0 = unlimited)")
+	fmt.Println("  --page N         For multi-page TIFF input, select page N (default 0, the first page)")
+	fmt.Println("  --filter         Resize filter: nearest|bilinear|bicubic|lanczos3 (default nearest)")
+	fmt.Println("  --smooth R       Box blur radius to run before --posterize (0 = disabled)")
+	fmt.Println("  --posterize N    Snap each color channel to N levels before block-finding (0 = disabled)")
+	fmt.Println("  --verbose        Print extra detail, e.g. block counts with/without --smooth/--posterize")
 	fmt.Println()
-	fmt.Println("Supported formats: JPG, JPEG, PNG, GIF, BMP, TIFF")
+	fmt.Println("Supported formats: JPG, JPEG, PNG, GIF, BMP, TIFF, WEBP")
 }
 
-// Validate input file exists, is readable, and has supported format
-func validateInputFile(path string) error {
-	// Check if file exists
-	fileInfo, err := os.Stat(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return fmt.Errorf("file does not exist: %s", path)
-		}
-		return fmt.Errorf("cannot access file: %s - %v", path, err)
-	}
+// batchStats aggregates totals across every file processed in directory
+// mode, reported once all workers have finished. It also drives the
+// aggregate progress line runBatch prints in place of each worker's own
+// (otherwise interleaved) per-file progress bar.
+type batchStats struct {
+	mu            sync.Mutex
+	filesFound    int
+	converted     int
+	skipped       int
+	failed        int
+	inputBytes    int64
+	outputBytes   int64
+	blocksEmitted int64
+	lastUpdate    time.Time
+}
 
-	// Check if it's a directory
-	if fileInfo.IsDir() {
-		return fmt.Errorf("path is a directory, not a file: %s", path)
+func (s *batchStats) recordSuccess(r *conversionResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.skipped {
+		s.skipped++
+		return
 	}
+	s.converted++
+	s.inputBytes += r.inputSize
+	s.outputBytes += r.outputSize
+	s.blocksEmitted += int64(r.blocks)
+}
 
-	// Check file size (minimum 1 byte, maximum 500MB)
-	if fileInfo.Size() == 0 {
-		return fmt.Errorf("file is empty: %s", path)
-	}
-	if fileInfo.Size() > 500*1024*1024 {
-		return fmt.Errorf("file too large (max 500MB): %s (%s)", path, formatFileSize(fileInfo.Size()))
-	}
+func (s *batchStats) recordFailure() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed++
+}
 
-	// Check file extension
-	ext := strings.ToLower(filepath.Ext(path))
-	if !supportedFormats[ext] {
-		supported := make([]string, 0, len(supportedFormats))
-		for format := range supportedFormats {
-			supported = append(supported, format)
-		}
-		return fmt.Errorf("unsupported format: %s (supported: %s)", ext, strings.Join(supported, ", "))
+// printProgress prints a single aggregate files/blocks/bytes progress line,
+// throttled the same way ProgressTracker throttles its own updates. Workers
+// call this after every completed job instead of each running its own
+// ProgressTracker, since multiple \r-based bars writing to stdout
+// concurrently garbles the output.
+func (s *batchStats) printProgress() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	done := s.converted + s.skipped + s.failed
+	if done < s.filesFound && time.Since(s.lastUpdate) < 100*time.Millisecond {
+		return
 	}
+	s.lastUpdate = time.Now()
+	fmt.Printf("\rFiles: %d/%d  Blocks emitted: %d  Output: %s", done, s.filesFound, s.blocksEmitted, formatFileSize(s.outputBytes))
+}
 
-	// Check read permissions
-	file, err := os.Open(path)
-	if err != nil {
-		return fmt.Errorf("cannot read file (permission denied?): %s", path)
+// runBatch walks inputDir (optionally recursively), converts every
+// supported image it finds using a pool of --jobs workers, and prints a
+// summary table when it's done. outputDir mirrors the input tree when it
+// names (or will name) a directory; otherwise each image gets a sibling
+// .svg next to the input. width, height and colors are the same --width/
+// --height/--colors values the single-file path honors.
+func runBatch(inputDir, outputDir string, width, height, colors int) error {
+	batchJobs := collectBatchJobs(inputDir, outputDir)
+
+	numWorkers := workerCount()
+	jobCh := make(chan batchJob, len(batchJobs))
+	for _, j := range batchJobs {
+		jobCh <- j
+	}
+	close(jobCh)
+
+	stats := &batchStats{filesFound: len(batchJobs)}
+	opts := convertOptions{width: width, height: height, colors: colors, background: backgroundHex, preserveAlpha: preserveAlpha, preproc: preprocSpec, smooth: smoothRadius, posterize: posterizeLevels}
+
+	// convertFile's own diagnostics and ProgressTracker aren't safe to
+	// interleave across --jobs workers, so each worker runs the per-file
+	// pipeline quiet; stats.printProgress reports one aggregate line instead.
+	reportProgress := !quiet
+	quiet = true
+
+	var wg sync.WaitGroup
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobCh {
+				result, err := convertFile(j.input, j.output, opts)
+				if err != nil {
+					log.Printf("failed: %s: %v", j.input, err)
+					stats.recordFailure()
+				} else {
+					stats.recordSuccess(result)
+				}
+				if reportProgress {
+					stats.printProgress()
+				}
+			}
+		}()
 	}
-	file.Close()
+	wg.Wait()
+	quiet = !reportProgress
 
+	if reportProgress {
+		fmt.Println()
+	}
+	printBatchSummary(stats)
 	return nil
 }
 
-// Validate output file path and permissions
-func validateOutputFile(path string, forceOverwrite bool) error {
-	// Check if output directory exists
-	dir := filepath.Dir(path)
-	if dir != "." {
-		if _, err := os.Stat(dir); os.IsNotExist(err) {
-			return fmt.Errorf("output directory does not exist: %s", dir)
+type batchJob struct {
+	input, output string
+}
+
+// collectBatchJobs walks inputDir, filters entries by supportedFormats and
+// --ignore-suffix, and computes each file's output path: mirrored under
+// outputDir when outputDir is given, otherwise a sibling .svg.
+func collectBatchJobs(inputDir, outputDir string) []batchJob {
+	var jobsFound []batchJob
+
+	walkFn := func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			log.Printf("walk error: %s: %v", path, err)
+			return nil
+		}
+		if info.IsDir() {
+			if path != inputDir && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ignoreSuffix != "" && strings.HasSuffix(info.Name(), ignoreSuffix) {
+			return nil
+		}
+		if !supportedFormats[strings.ToLower(filepath.Ext(path))] {
+			return nil
 		}
-	}
 
-	// Check if output file already exists
-	if _, err := os.Stat(path); err == nil {
-		if !forceOverwrite {
-			return fmt.Errorf("output file already exists: %s (use --force to overwrite)", path)
+		var out string
+		if outputDir != "" {
+			rel, relErr := filepath.Rel(inputDir, path)
+			if relErr != nil {
+				rel = filepath.Base(path)
+			}
+			out = filepath.Join(outputDir, rel)
+			out = autoGenerateOutputName(out)
+		} else {
+			out = autoGenerateOutputName(path)
 		}
-	} else if !os.IsNotExist(err) {
-		// Some other error checking file existence
-		return fmt.Errorf("cannot check output file: %s - %v", path, err)
+
+		jobsFound = append(jobsFound, batchJob{input: path, output: out})
+		return nil
 	}
 
-	// Check if we have write permissions in the directory
-	testFile := filepath.Join(dir, ".pixel2svg_write_test")
-	if err := os.WriteFile(testFile, []byte("test"), 0644); err != nil {
-		return fmt.Errorf("no write permission in directory: %s", dir)
+	if err := filepath.Walk(inputDir, walkFn); err != nil {
+		log.Printf("walk error: %v", err)
 	}
-	os.Remove(testFile)
 
-	// Validate output file extension
-	ext := strings.ToLower(filepath.Ext(path))
-	if ext != ".svg" {
-		return fmt.Errorf("output file must have .svg extension: %s", path)
+	if outputDir != "" {
+		for _, j := range jobsFound {
+			if err := os.MkdirAll(filepath.Dir(j.output), 0755); err != nil {
+				log.Printf("cannot create output directory for %s: %v", j.output, err)
+			}
+		}
 	}
 
-	return nil
+	return jobsFound
 }
 
-// Validate resize dimensions
-func validateDimensions(width, height int) error {
-	if width < 0 || height < 0 {
-		return fmt.Errorf("dimensions cannot be negative: width=%d, height=%d", width, height)
-	}
-	if width > 100000 || height > 100000 {
-		return fmt.Errorf("dimensions too large (max 100000): width=%d, height=%d", width, height)
+func workerCount() int {
+	if jobs < 1 {
+		return 1
 	}
-	if width == 0 && height == 0 {
-		return fmt.Errorf("both width and height cannot be zero")
-	}
-	return nil
+	return jobs
 }
 
-// Validate image dimensions after loading
-func validateImageDimensions(width, height int) error {
-	if width <= 0 || height <= 0 {
-		return fmt.Errorf("invalid image dimensions: %dx%d", width, height)
+// imageWorkerCount caps intra-image parallelism (createColorGrid's row
+// stripes, findOptimalBlocks' bands). It's deliberately separate from
+// workerCount/--jobs: in directory mode, --jobs file workers each also
+// running --jobs-many intra-image goroutines would spawn jobs^2 goroutines
+// contending for CPU.
+func imageWorkerCount() int {
+	if imageWorkers < 1 {
+		return 1
 	}
-	if width > 30000 || height > 30000 {
-		return fmt.Errorf("image too large (max 30000x30000): %dx%d", width, height)
-	}
-	if width*height > 500000000 {
-		return fmt.Errorf("image has too many pixels (max 500 million): %dx%d = %d pixels", 
-			width, height, width*height)
+	return imageWorkers
+}
+
+func printBatchSummary(s *batchStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reduction := 0.0
+	if s.inputBytes > 0 {
+		reduction = float64(s.inputBytes-s.outputBytes) / float64(s.inputBytes) * 100
 	}
-	return nil
+
+	fmt.Println("Batch conversion complete:")
+	fmt.Printf("  Files found:     %d\n", s.filesFound)
+	fmt.Printf("  Converted:       %d\n", s.converted)
+	fmt.Printf("  Skipped:         %d\n", s.skipped)
+	fmt.Printf("  Failed:          %d\n", s.failed)
+	fmt.Printf("  Input size:      %s\n", formatFileSize(s.inputBytes))
+	fmt.Printf("  Output size:     %s\n", formatFileSize(s.outputBytes))
+	fmt.Printf("  Avg reduction:   %.1f%%\n", reduction)
+	fmt.Printf("  Blocks emitted:  %d\n", s.blocksEmitted)
 }
 
-// Load and validate image file
-func loadAndValidateImage(path string) (image.Image, error) {
-	file, err := os.Open(path)
+// gifFrameBlocks holds the optimized blocks for a single GIF frame plus
+// how long that frame should stay on screen.
+type gifFrameBlocks struct {
+	blocks []Block
+	delay  time.Duration
+}
+
+// convertAnimatedGIF decodes every frame of a multi-frame GIF, runs the
+// existing block-finding pipeline on each frame independently, and writes
+// a single animated SVG that uses SMIL <set> timing to show one frame at
+// a time.
+func convertAnimatedGIF(input, output string, opts convertOptions) error {
+	file, err := os.Open(input)
 	if err != nil {
-		return nil, fmt.Errorf("cannot open image file: %v", err)
+		return fmt.Errorf("cannot open image file: %v", err)
 	}
 	defer file.Close()
 
-	// Try to decode the image
-	img, format, err := image.Decode(file)
+	decoded, err := gif.DecodeAll(file)
 	if err != nil {
-		return nil, fmt.Errorf("cannot decode image (corrupted or unsupported format): %v", err)
+		return fmt.Errorf("cannot decode GIF (corrupted or not a GIF?): %v", err)
 	}
-
-	if !quiet {
-		fmt.Printf("Detected format: %s\n", format)
+	if len(decoded.Image) == 0 {
+		return fmt.Errorf("GIF has no frames: %s", input)
 	}
 
-	return img, nil
-}
-
-// Calculate size reduction percentage
-func calculateSizeReduction(inputSize, outputSize int64) float64 {
-	if inputSize == 0 {
-		return 0
+	var bg color.RGBA
+	if !opts.preserveAlpha {
+		bg, err = parseHexColor(opts.background)
+		if err != nil {
+			return fmt.Errorf("background color error: %w", err)
+		}
 	}
-	return float64(inputSize-outputSize) / float64(inputSize) * 100
-}
 
-// Print conversion summary
-func printConversionSummary(input, output string, inputSize, outputSize int64, reduction float64, width, height, blocks int, duration time.Duration) {
-	fmt.Printf("Conversion complete:\n")
-	fmt.Printf("  Input:  %s (%s)\n", filepath.Base(input), formatFileSize(inputSize))
-	fmt.Printf("  Output: %s (%s)\n", filepath.Base(output), formatFileSize(outputSize))
-	fmt.Printf("  Size reduction: %.1f%%\n", reduction)
-	fmt.Printf("  Dimensions: %dx%d pixels\n", width, height)
-	fmt.Printf("  Optimization: %d blocks (%.1fx compression)\n", blocks, float64(width*height)/float64(blocks))
-	fmt.Printf("  Time: %v\n", duration.Round(time.Millisecond))
-}
+	bounds := decoded.Image[0].Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if opts.width > 0 || opts.height > 0 {
+		w, h = calculateSize(w, h, opts.width, opts.height)
+	}
 
-// Get file size in bytes
-func getFileSize(path string) (int64, error) {
-	fileInfo, err := os.Stat(path)
-	if err != nil {
-		return 0, err
+	if err := validateImageDimensions(w, h); err != nil {
+		return fmt.Errorf("image dimension validation: %w", err)
 	}
-	return fileInfo.Size(), nil
-}
 
-// Format file size to human readable string
-func formatFileSize(bytes int64) string {
-	const (
-		KB = 1024
-		MB = KB * 1024
-		GB = MB * 1024
-	)
-
-	switch {
-	case bytes >= GB:
-		return fmt.Sprintf("%.1f GB", float64(bytes)/float64(GB))
-	case bytes >= MB:
-		return fmt.Sprintf("%.1f MB", float64(bytes)/float64(MB))
-	case bytes >= KB:
-		return fmt.Sprintf("%.1f KB", float64(bytes)/float64(KB))
-	default:
-		return fmt.Sprintf("%d bytes", bytes)
+	if !quiet {
+		fmt.Printf("Decoded %d frames (%dx%d)\n", len(decoded.Image), w, h)
 	}
-}
 
-// Auto-generate output filename from input
-func autoGenerateOutputName(inputPath string) string {
-	ext := filepath.Ext(inputPath)
-	base := inputPath[:len(inputPath)-len(ext)]
-	return base + ".svg"
-}
+	progress := NewProgressTracker(len(decoded.Image)*w*h, quiet)
 
-// ProgressTracker handles progress display
-type ProgressTracker struct {
-	total      int
-	processed  int
-	quiet      bool
-	startTime  time.Time
-	lastUpdate time.Time
-}
+	frames := make([]gifFrameBlocks, len(decoded.Image))
+	for i, frameImg := range decoded.Image {
+		var frame image.Image = frameImg
+		if opts.width > 0 || opts.height > 0 {
+			frame, err = resizeImage(frame, opts.width, opts.height)
+			if err != nil {
+				return fmt.Errorf("resize error: %w", err)
+			}
+		}
+		if opts.preproc != "" {
+			frame, err = applyPreproc(frame, opts.preproc)
+			if err != nil {
+				return fmt.Errorf("preprocessing error: %w", err)
+			}
+		}
+		if !opts.preserveAlpha {
+			frame = matteImage(frame, bg)
+		}
+		if opts.smooth > 0 || opts.posterize > 0 {
+			frame = applySmoothPosterize(frame, opts.smooth, opts.posterize)
+		}
+
+		delay := time.Duration(decoded.Delay[i]) * 10 * time.Millisecond
+		if fpsOverride > 0 {
+			delay = time.Duration(float64(time.Second) / fpsOverride)
+		}
+		if delay <= 0 {
+			delay = 100 * time.Millisecond
+		}
+
+		grid := createColorGrid(frame, w, h, progress)
+		frames[i] = gifFrameBlocks{
+			blocks: findOptimalBlocks(grid, w, h),
+			delay:  delay,
+		}
+	}
 
-func NewProgressTracker(total int, quiet bool) *ProgressTracker {
-	return &ProgressTracker{
-		total:      total,
-		quiet:      quiet,
-		startTime:  time.Now(),
-		lastUpdate: time.Now(),
+	if err := writeAnimatedSVG(frames, w, h, output); err != nil {
+		return fmt.Errorf("error writing animated SVG: %w", err)
 	}
+
+	progress.Finish()
+	return nil
 }
 
-func (p *ProgressTracker) Update(increment int) {
-	if p.quiet {
-		return
+// writeAnimatedSVG writes one <g> per frame, each containing that frame's
+// <rect>s, and toggles each group's display with SMIL <set> elements timed
+// from the cumulative frame delays so exactly one frame is visible at once.
+func writeAnimatedSVG(frames []gifFrameBlocks, w, h int, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
 	}
-	
-	p.processed += increment
-	
-	if time.Since(p.lastUpdate) < 100*time.Millisecond && p.processed < p.total {
-		return
+	defer file.Close()
+
+	total := time.Duration(0)
+	for _, f := range frames {
+		total += f.delay
 	}
-	p.lastUpdate = time.Now()
-	
-	percent := float64(p.processed) / float64(p.total) * 100
-	barWidth := 50
-	completed := int(float64(barWidth) * percent / 100)
-	
-	bar := "["
-	for i := 0; i < barWidth; i++ {
-		if i < completed {
-			bar += "="
-		} else if i == completed {
-			bar += ">"
-		} else {
-			bar += " "
+	totalSecs := total.Seconds()
+
+	file.WriteString(`<?xml version="1.0" encoding="UTF-8"?>`)
+	file.WriteString(`<svg width="` + strconv.Itoa(w) + `" height="` + strconv.Itoa(h) + `" xmlns="http://www.w3.org/2000/svg">`)
+
+	elapsed := time.Duration(0)
+	for i, f := range frames {
+		begin := elapsed.Seconds()
+		elapsed += f.delay
+		end := elapsed.Seconds()
+
+		display := "inline"
+		if i != 0 {
+			display = "none"
 		}
+		file.WriteString(`<g id="f` + strconv.Itoa(i) + `" display="` + display + `">`)
+		for _, b := range f.blocks {
+			if b.A == 0 {
+				continue
+			}
+			opacity := ""
+			if b.A < 255 {
+				opacity = ` fill-opacity="` + strconv.FormatFloat(float64(b.A)/255.0, 'f', 3, 64) + `"`
+			}
+			file.WriteString(`<rect x="` + strconv.Itoa(b.X) + `" y="` + strconv.Itoa(b.Y) +
+				`" width="` + strconv.Itoa(b.Width) + `" height="` + strconv.Itoa(b.Height) +
+				`" fill="rgb(` + strconv.Itoa(int(b.R)) + `,` + strconv.Itoa(int(b.G)) + `,` + strconv.Itoa(int(b.B)) + `)"` + opacity + `/>`)
+		}
+		if i != 0 {
+			file.WriteString(`<set attributeName="display" to="inline" begin="` + formatSeconds(begin) + `s;` + formatSeconds(begin+totalSecs) + `s" dur="` + formatSeconds(f.delay.Seconds()) + `s"/>`)
+		}
+		file.WriteString(`<set attributeName="display" to="none" begin="` + formatSeconds(end) + `s;` + formatSeconds(end+totalSecs) + `s" dur="` + formatSeconds(f.delay.Seconds()) + `s"/>`)
+		file.WriteString(`</g>`)
 	}
-	bar += "]"
-	
-	elapsed := time.Since(p.startTime)
-	eta := time.Duration(0)
-	if percent > 0 {
-		totalEstimate := time.Duration(float64(elapsed) / percent * 100)
-		eta = totalEstimate - elapsed
-	}
-	
-	fmt.Printf("\r%s %.1f%% ETA: %v", bar, percent, eta.Round(time.Second))
-}
 
-func (p *ProgressTracker) Finish() {
-	if p.quiet {
-		return
-	}
-	fmt.Printf("\r[==================================================] 100.0%% ETA: 0s\n")
+	file.WriteString(`</svg>`)
+	return nil
 }
 
-func fileExists(path string) bool {
-	_, err := os.Stat(path)
-	return err == nil
+func formatSeconds(s float64) string {
+	return strconv.FormatFloat(s, 'f', 3, 64)
 }
 
-func loadImage(path string) (image.Image, error) {
+// Load and validate image file
+func loadAndValidateImage(path string) (image.Image, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if pageNum != 0 && (ext == ".tiff" || ext == ".tif") {
+		img, err := loadTIFFPage(path, pageNum)
+		if err != nil {
+			return nil, err
+		}
+		if !quiet {
+			fmt.Printf("Detected format: tiff (page %d)\n", pageNum)
+		}
+		return img, nil
+	}
+
 	file, err := os.Open(path)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("cannot open image file: %v", err)
 	}
 	defer file.Close()
-	img, _, err := image.Decode(file)
-	return img, err
+
+	// Try to decode the image
+	img, format, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode image (corrupted or unsupported format): %v", err)
+	}
+
+	if !quiet {
+		fmt.Printf("Detected format: %s\n", format)
+	}
+
+	return img, nil
 }
 
-func resizeImage(img image.Image, maxW, maxH int) image.Image {
+func resizeImage(img image.Image, maxW, maxH int) (image.Image, error) {
 	bounds := img.Bounds()
 	w, h := bounds.Dx(), bounds.Dy()
 
 	if maxW == 0 && maxH == 0 {
-		return img
+		return img, nil
 	}
 
 	newW, newH := calculateSize(w, h, maxW, maxH)
 	if newW == w && newH == h {
-		return img
+		return img, nil
 	}
 
-	resized := image.NewRGBA(image.Rect(0, 0, newW, newH))
-	scaleX, scaleY := float64(w)/float64(newW), float64(h)/float64(newH)
+	var resized image.Image
+	if filterName == filterNearest || filterName == "" {
+		out := image.NewRGBA(image.Rect(0, 0, newW, newH))
+		scaleX, scaleY := float64(w)/float64(newW), float64(h)/float64(newH)
 
-	for y := 0; y < newH; y++ {
-		for x := 0; x < newW; x++ {
-			srcX, srcY := int(float64(x)*scaleX), int(float64(y)*scaleY)
-			resized.Set(x, y, img.At(srcX, srcY))
+		for y := 0; y < newH; y++ {
+			for x := 0; x < newW; x++ {
+				srcX, srcY := int(float64(x)*scaleX), int(float64(y)*scaleY)
+				out.Set(x, y, img.At(srcX, srcY))
+			}
+		}
+		resized = out
+	} else {
+		var err error
+		resized, err = resizeWithFilter(img, newW, newH, filterName)
+		if err != nil {
+			return nil, err
 		}
 	}
 
 	if !quiet {
-		fmt.Printf("Resized: %dx%d -> %dx%d\n", w, h, newW, newH)
+		fmt.Printf("Resized: %dx%d -> %dx%d (%s)\n", w, h, newW, newH, filterName)
 	}
-	return resized
+	return resized, nil
 }
 
 func calculateSize(w, h, maxW, maxH int) (int, int) {
@@ -479,142 +757,3 @@ func calculateSize(w, h, maxW, maxH int) (int, int) {
 	return int(float64(maxH) * ratio), maxH
 }
 
-func findOptimalBlocks(img image.Image, w, h int, progress *ProgressTracker) []Block {
-	if !quiet {
-		fmt.Printf("Analyzing image...\n")
-	}
-
-	grid := make([][]uint32, h)
-	for y := 0; y < h; y++ {
-		grid[y] = make([]uint32, w)
-		for x := 0; x < w; x++ {
-			r, g, b, _ := img.At(x, y).RGBA()
-			grid[y][x] = (uint32(r>>8) << 16) | (uint32(g>>8) << 8) | uint32(b>>8)
-			progress.Update(1)
-		}
-	}
-
-	used := make([][]bool, h)
-	for i := range used {
-		used[i] = make([]bool, w)
-	}
-
-	var blocks []Block
-
-	if !quiet {
-		fmt.Printf("Finding optimal blocks...\n")
-	}
-
-	for y := 0; y < h; y++ {
-		for x := 0; x < w; x++ {
-			if used[y][x] {
-				continue
-			}
-
-			color := grid[y][x]
-			maxW := findMaxWidth(grid, x, y, color, w)
-			maxH := findMaxHeight(grid, x, y, color, maxW, h)
-
-			for {
-				expanded := false
-				
-				if x+maxW < w {
-					canExpand := true
-					for i := y; i < y+maxH; i++ {
-						if used[i][x+maxW] || grid[i][x+maxW] != color {
-							canExpand = false
-							break
-						}
-					}
-					if canExpand {
-						maxW++
-						expanded = true
-					}
-				}
-
-				if y+maxH < h {
-					canExpand := true
-					for i := x; i < x+maxW; i++ {
-						if used[y+maxH][i] || grid[y+maxH][i] != color {
-							canExpand = false
-							break
-						}
-					}
-					if canExpand {
-						maxH++
-						expanded = true
-					}
-				}
-
-				if !expanded {
-					break
-				}
-			}
-
-			r := uint8(color >> 16)
-			g := uint8(color >> 8)
-			b := uint8(color)
-			
-			blocks = append(blocks, Block{x, y, maxW, maxH, r, g, b})
-			
-			for i := y; i < y+maxH && i < h; i++ {
-				for j := x; j < x+maxW && j < w; j++ {
-					used[i][j] = true
-				}
-			}
-
-			x += maxW - 1
-		}
-	}
-
-	return blocks
-}
-
-func findMaxWidth(grid [][]uint32, x, y int, color uint32, maxX int) int {
-	w := 1
-	for x+w < maxX && grid[y][x+w] == color {
-		w++
-	}
-	return w
-}
-
-func findMaxHeight(grid [][]uint32, x, y int, color uint32, width, maxY int) int {
-	h := 1
-	for y+h < maxY {
-		for i := 0; i < width; i++ {
-			if grid[y+h][x+i] != color {
-				return h
-			}
-		}
-		h++
-	}
-	return h
-}
-
-func writeSVG(blocks []Block, w, h int, path string, progress *ProgressTracker) error {
-	if !quiet {
-		fmt.Printf("Writing SVG file...\n")
-	}
-
-	file, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	file.WriteString(`<?xml version="1.0" encoding="UTF-8"?>`)
-	file.WriteString(`<svg width="` + strconv.Itoa(w) + `" height="` + strconv.Itoa(h) + `" xmlns="http://www.w3.org/2000/svg">`)
-
-	for i, b := range blocks {
-		file.WriteString(`<rect x="` + strconv.Itoa(b.x) + `" y="` + strconv.Itoa(b.y) + 
-			`" width="` + strconv.Itoa(b.w) + `" height="` + strconv.Itoa(b.h) + 
-			`" fill="rgb(` + strconv.Itoa(int(b.r)) + `,` + strconv.Itoa(int(b.g)) + `,` + strconv.Itoa(int(b.b)) + `)"/>`)
-		
-		if i%100 == 0 {
-			progress.Update(0)
-		}
-	}
-
-	file.WriteString(`</svg>`)
-	return nil
-}
\ No newline at end of file
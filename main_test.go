@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatSeconds(t *testing.T) {
+	if got := formatSeconds(1.5); got != "1.500" {
+		t.Errorf("formatSeconds(1.5) = %q, want %q", got, "1.500")
+	}
+}
+
+func TestWriteAnimatedSVG(t *testing.T) {
+	frames := []gifFrameBlocks{
+		{
+			blocks: []Block{
+				{X: 0, Y: 0, Width: 1, Height: 1, R: 255, G: 0, B: 0, A: 255},
+				{X: 1, Y: 0, Width: 1, Height: 1, R: 0, G: 0, B: 0, A: 0}, // transparent, should be skipped
+			},
+			delay: 100 * time.Millisecond,
+		},
+		{
+			blocks: []Block{
+				{X: 0, Y: 0, Width: 1, Height: 1, R: 0, G: 255, B: 0, A: 128},
+			},
+			delay: 200 * time.Millisecond,
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "anim.svg")
+	if err := writeAnimatedSVG(frames, 2, 1, path); err != nil {
+		t.Fatalf("writeAnimatedSVG: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+
+	if strings.Count(out, "<g id=") != 2 {
+		t.Fatalf("expected one <g> per frame, got: %s", out)
+	}
+	if !strings.Contains(out, `<g id="f0" display="inline">`) {
+		t.Errorf("first frame group should start visible, got: %s", out)
+	}
+	if !strings.Contains(out, `<g id="f1" display="none">`) {
+		t.Errorf("non-first frame groups should start hidden, got: %s", out)
+	}
+	// Frame 0 has one opaque block and one transparent block (skipped);
+	// frame 1 has one partially-opaque block. 2 rects total.
+	if strings.Count(out, "<rect") != 2 {
+		t.Errorf("expected exactly 2 emitted rects (transparent block skipped), got: %s", out)
+	}
+	if !strings.Contains(out, `fill="rgb(255,0,0)"`) {
+		t.Errorf("expected frame 0's opaque block as an rgb() fill, got: %s", out)
+	}
+	if !strings.Contains(out, `fill-opacity="0.502"`) {
+		t.Errorf("expected frame 1's partial alpha rendered as fill-opacity, got: %s", out)
+	}
+	// Every non-first frame needs a "to=inline" <set> toggling it on, and
+	// every frame needs a "to=none" <set> toggling it back off.
+	if strings.Count(out, `to="inline"`) != 1 {
+		t.Errorf("expected exactly 1 \"to=inline\" <set> (frame 0 starts visible already), got: %s", out)
+	}
+	if strings.Count(out, `to="none"`) != 2 {
+		t.Errorf("expected one \"to=none\" <set> per frame, got: %s", out)
+	}
+}
+
+func TestWorkerCountFloor(t *testing.T) {
+	prev := jobs
+	defer func() { jobs = prev }()
+
+	jobs = 0
+	if got := workerCount(); got < 1 {
+		t.Errorf("workerCount() should never return less than 1, got %d", got)
+	}
+}
+
+func TestImageWorkerCountFloor(t *testing.T) {
+	prev := imageWorkers
+	defer func() { imageWorkers = prev }()
+
+	imageWorkers = 0
+	if got := imageWorkerCount(); got != 1 {
+		t.Errorf("imageWorkerCount() with imageWorkers=0 should floor to 1, got %d", got)
+	}
+
+	imageWorkers = 4
+	if got := imageWorkerCount(); got != 4 {
+		t.Errorf("imageWorkerCount() should pass through a positive --image-workers value, got %d", got)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"image"
+	"image/color"
+)
+
+// applySmoothPosterize runs --smooth (a box blur denoiser) followed by
+// --posterize (channel quantization) before block-finding, trading a small
+// amount of visual fidelity for longer same-color runs. Either stage is
+// skipped when its radius/level count is <= 0, so existing behavior is
+// unchanged bit-for-bit when neither flag is set.
+func applySmoothPosterize(img image.Image, radius, levels int) image.Image {
+	if radius > 0 {
+		img = boxBlur(img, radius)
+	}
+	if levels > 0 {
+		img = posterizeImage(img, levels)
+	}
+	return img
+}
+
+// boxBlur applies a separable box blur of the given radius: a horizontal
+// pass followed by a vertical pass, each a sliding window sum. Fully
+// transparent pixels are excluded from the R/G/B sums (but not the alpha
+// sum) so they don't bleed dark or otherwise wrong colors into the edges of
+// transparent PNGs.
+func boxBlur(img image.Image, radius int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	type px struct{ r, g, b, a int }
+
+	rows := make([][]px, h)
+	for y := 0; y < h; y++ {
+		rows[y] = make([]px, w)
+		for x := 0; x < w; x++ {
+			r, g, b, a := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			rows[y][x] = px{int(r >> 8), int(g >> 8), int(b >> 8), int(a >> 8)}
+		}
+	}
+
+	blurLine := func(line []px, i, n int) px {
+		lo, hi := clampInt(i-radius, 0, n-1), clampInt(i+radius, 0, n-1)
+		var sr, sg, sb, sa, colorCount, total int
+		for j := lo; j <= hi; j++ {
+			p := line[j]
+			sa += p.a
+			total++
+			if p.a > 0 {
+				sr += p.r
+				sg += p.g
+				sb += p.b
+				colorCount++
+			}
+		}
+		out := px{a: sa / total}
+		if colorCount > 0 {
+			out.r, out.g, out.b = sr/colorCount, sg/colorCount, sb/colorCount
+		}
+		return out
+	}
+
+	horiz := make([][]px, h)
+	for y := 0; y < h; y++ {
+		horiz[y] = make([]px, w)
+		for x := 0; x < w; x++ {
+			horiz[y][x] = blurLine(rows[y], x, w)
+		}
+	}
+
+	out := image.NewNRGBA(bounds)
+	col := make([]px, h)
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			col[y] = horiz[y][x]
+		}
+		for y := 0; y < h; y++ {
+			p := blurLine(col, y, h)
+			out.SetNRGBA(bounds.Min.X+x, bounds.Min.Y+y, color.NRGBA{R: uint8(p.r), G: uint8(p.g), B: uint8(p.b), A: uint8(p.a)})
+		}
+	}
+
+	return out
+}
+
+// posterizeImage snaps every R/G/B channel to one of levels evenly spaced
+// values, leaving alpha untouched. Reducing the number of distinct values
+// directly lengthens the runs findMaxWidthRGBA finds.
+func posterizeImage(img image.Image, levels int) image.Image {
+	bounds := img.Bounds()
+	out := image.NewNRGBA(bounds)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			out.SetNRGBA(x, y, color.NRGBA{
+				R: posterizeChannel(uint8(r>>8), levels),
+				G: posterizeChannel(uint8(g>>8), levels),
+				B: posterizeChannel(uint8(b>>8), levels),
+				A: uint8(a >> 8),
+			})
+		}
+	}
+
+	return out
+}
+
+func posterizeChannel(v uint8, levels int) uint8 {
+	if levels <= 1 {
+		return 0
+	}
+	step := 255.0 / float64(levels-1)
+	level := int(float64(v)*float64(levels-1)/255.0 + 0.5)
+	return uint8(float64(level) * step)
+}
+
+// countBlocksWithout re-runs quantization and block-finding over img (which
+// has not had --smooth/--posterize applied) so --verbose can report how
+// many fewer blocks the preprocessing bought. Its own progress tracker is
+// built quiet regardless of the global --quiet flag, since this is purely
+// an informational side computation.
+func countBlocksWithout(img image.Image, opts convertOptions) int {
+	if opts.colors > 0 {
+		if quantizer, err := NewQuantizer(quantizerName); err == nil {
+			paletted, _ := quantizer.Quantize(img, opts.colors)
+			img = paletted
+		}
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	progress := NewProgressTracker(w*h, true)
+	grid := createColorGrid(img, w, h, progress)
+	return len(findOptimalBlocks(grid, w, h))
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestPosterizeChannelLevels(t *testing.T) {
+	if got := posterizeChannel(128, 0); got != 0 {
+		t.Errorf("levels<=1 should collapse to 0, got %d", got)
+	}
+	if got := posterizeChannel(0, 2); got != 0 {
+		t.Errorf("posterizeChannel(0, 2) = %d, want 0", got)
+	}
+	if got := posterizeChannel(255, 2); got != 255 {
+		t.Errorf("posterizeChannel(255, 2) = %d, want 255", got)
+	}
+	if got := posterizeChannel(255, 4); got != 255 {
+		t.Errorf("posterizeChannel(255, 4) = %d, want 255", got)
+	}
+}
+
+func TestPosterizeImageReducesDistinctValues(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 256, 1))
+	for x := 0; x < 256; x++ {
+		img.Set(x, 0, color.NRGBA{R: uint8(x), A: 255})
+	}
+
+	out := posterizeImage(img, 2)
+	seen := map[uint8]bool{}
+	for x := 0; x < 256; x++ {
+		r, _, _, _ := out.At(x, 0).RGBA()
+		seen[uint8(r>>8)] = true
+	}
+	if len(seen) > 2 {
+		t.Errorf("posterizing to 2 levels should yield at most 2 distinct R values, got %d: %v", len(seen), seen)
+	}
+}
+
+func TestPosterizeImagePreservesAlpha(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	img.Set(0, 0, color.NRGBA{R: 100, G: 150, B: 200, A: 77})
+
+	out := posterizeImage(img, 4)
+	_, _, _, a := out.At(0, 0).RGBA()
+	if uint8(a>>8) != 77 {
+		t.Errorf("posterizeImage should leave alpha untouched, got %d, want 77", a>>8)
+	}
+}
+
+func TestBoxBlurSmoothsFlatRegion(t *testing.T) {
+	const w, h = 9, 9
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{R: 100, G: 100, B: 100, A: 255})
+		}
+	}
+	img.Set(4, 4, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+
+	out := boxBlur(img, 2)
+	r, g, b, _ := out.At(4, 4).RGBA()
+	if uint8(r>>8) == 255 || uint8(g>>8) == 255 || uint8(b>>8) == 255 {
+		t.Errorf("box blur should smooth the single bright outlier, got %d,%d,%d", r>>8, g>>8, b>>8)
+	}
+}
+
+func TestBoxBlurIgnoresTransparentPixelsInColorAverage(t *testing.T) {
+	const w, h = 5, 1
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	img.Set(0, 0, color.NRGBA{R: 0, G: 0, B: 0, A: 0})
+	for x := 1; x < w; x++ {
+		img.Set(x, 0, color.NRGBA{R: 200, G: 200, B: 200, A: 255})
+	}
+
+	out := boxBlur(img, 1).(*image.NRGBA)
+	// Pixel 1's neighborhood is {transparent, 200, 200}; the transparent
+	// pixel contributes to the alpha average but must not drag R/G/B toward
+	// black. Read the non-premultiplied NRGBA components directly, since
+	// At(...).RGBA() would premultiply by the (partial) averaged alpha.
+	c := out.NRGBAAt(1, 0)
+	if c.R != 200 || c.G != 200 || c.B != 200 {
+		t.Errorf("transparent neighbor should not bleed into color average, got %d,%d,%d", c.R, c.G, c.B)
+	}
+}
+
+func TestApplySmoothPosterizeSkipsZeroStages(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.NRGBA{R: 10, G: 20, B: 30, A: 255})
+
+	out := applySmoothPosterize(img, 0, 0)
+	if out != image.Image(img) {
+		t.Error("applySmoothPosterize with radius=0 and levels=0 should return img unchanged")
+	}
+}
+
+func TestCountBlocksWithoutCountsFlatImageAsOneBlock(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			img.Set(x, y, color.NRGBA{R: 50, G: 60, B: 70, A: 255})
+		}
+	}
+
+	if got := countBlocksWithout(img, convertOptions{}); got != 1 {
+		t.Errorf("a solid-color image should collapse to a single block, got %d", got)
+	}
+}
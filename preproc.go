@@ -0,0 +1,303 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"strings"
+)
+
+// applyPreproc runs the comma-separated list of preprocessing stages named
+// in spec, in order, before the image reaches createColorGrid. Recognized
+// stage names are "sauvola", "otsu", "median" and "bilateral"; an empty
+// spec is a no-op.
+func applyPreproc(img image.Image, spec string) (image.Image, error) {
+	if spec == "" {
+		return img, nil
+	}
+
+	for _, stage := range strings.Split(spec, ",") {
+		stage = strings.TrimSpace(stage)
+		switch stage {
+		case "":
+			continue
+		case "sauvola":
+			img = sauvolaBinarize(img)
+		case "otsu":
+			img = otsuBinarize(img)
+		case "median":
+			img = medianFilter(img)
+		case "bilateral":
+			img = bilateralFilter(img)
+		default:
+			return nil, fmt.Errorf("unknown --preproc stage %q (want sauvola|otsu|median|bilateral)", stage)
+		}
+	}
+
+	return img, nil
+}
+
+// Sauvola parameters, per Sauvola & Pietikainen 2000: k controls how much
+// the local standard deviation pulls the threshold down, w is the window
+// half-size, and R is the assumed dynamic range of the standard deviation.
+const (
+	sauvolaK      = 0.3
+	sauvolaWindow = 19
+	sauvolaR      = 128.0
+)
+
+// sauvolaBinarize converts img to grayscale luminance, then thresholds each
+// pixel against a local mean/stddev computed in O(1) per pixel from a pair
+// of integral images, producing a clean two-color image well suited to the
+// greedy rectangle merger.
+func sauvolaBinarize(img image.Image) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	gray := grayscaleValues(img)
+	sum, sumSq := buildIntegralImages(gray, w, h)
+
+	out := image.NewGray(bounds)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			x0, x1 := clampInt(x-sauvolaWindow, 0, w-1), clampInt(x+sauvolaWindow, 0, w-1)
+			y0, y1 := clampInt(y-sauvolaWindow, 0, h-1), clampInt(y+sauvolaWindow, 0, h-1)
+			n := float64((x1 - x0 + 1) * (y1 - y0 + 1))
+
+			mean := integralSum(sum, x0, y0, x1, y1) / n
+			variance := integralSum(sumSq, x0, y0, x1, y1)/n - mean*mean
+			if variance < 0 {
+				variance = 0
+			}
+			stddev := math.Sqrt(variance)
+
+			threshold := mean * (1 + sauvolaK*(stddev/sauvolaR-1))
+
+			val := uint8(0)
+			if gray[y][x] > threshold {
+				val = 255
+			}
+			out.SetGray(bounds.Min.X+x, bounds.Min.Y+y, color.Gray{Y: val})
+		}
+	}
+
+	return out
+}
+
+// otsuBinarize picks a single global threshold that maximizes the
+// between-class variance of the image's grayscale histogram, then applies
+// it to every pixel.
+func otsuBinarize(img image.Image) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	gray := grayscaleValues(img)
+
+	var hist [256]int
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			hist[uint8(gray[y][x])]++
+		}
+	}
+
+	threshold := otsuThreshold(hist, w*h)
+
+	out := image.NewGray(bounds)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			val := uint8(0)
+			if uint8(gray[y][x]) > threshold {
+				val = 255
+			}
+			out.SetGray(bounds.Min.X+x, bounds.Min.Y+y, color.Gray{Y: val})
+		}
+	}
+
+	return out
+}
+
+// otsuThreshold finds the grayscale level that maximizes between-class
+// variance given a 256-bucket histogram over total pixels.
+func otsuThreshold(hist [256]int, total int) uint8 {
+	var sumAll float64
+	for i, c := range hist {
+		sumAll += float64(i * c)
+	}
+
+	var sumB, weightB float64
+	maxVariance := -1.0
+	var threshold uint8
+
+	for t := 0; t < 256; t++ {
+		weightB += float64(hist[t])
+		if weightB == 0 {
+			continue
+		}
+		weightF := float64(total) - weightB
+		if weightF == 0 {
+			break
+		}
+
+		sumB += float64(t * hist[t])
+		meanB := sumB / weightB
+		meanF := (sumAll - sumB) / weightF
+
+		variance := weightB * weightF * (meanB - meanF) * (meanB - meanF)
+		if variance > maxVariance {
+			maxVariance = variance
+			threshold = uint8(t)
+		}
+	}
+
+	return threshold
+}
+
+// medianFilter replaces each pixel's R, G and B with the median of the 3x3
+// neighborhood around it (edges clamped), leaving alpha untouched. A cheap
+// general-purpose denoiser to run before quantization.
+func medianFilter(img image.Image) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	out := image.NewRGBA(bounds)
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var rs, gs, bs [9]uint8
+			n := 0
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					sx := clampInt(x+dx, 0, w-1)
+					sy := clampInt(y+dy, 0, h-1)
+					r, g, b, _ := img.At(bounds.Min.X+sx, bounds.Min.Y+sy).RGBA()
+					rs[n], gs[n], bs[n] = uint8(r>>8), uint8(g>>8), uint8(b>>8)
+					n++
+				}
+			}
+
+			_, _, _, a := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			out.SetRGBA(bounds.Min.X+x, bounds.Min.Y+y, color.RGBA{
+				R: medianOf9(rs), G: medianOf9(gs), B: medianOf9(bs), A: uint8(a >> 8),
+			})
+		}
+	}
+
+	return out
+}
+
+func medianOf9(v [9]uint8) uint8 {
+	sorted := v
+	sort.Slice(sorted[:], func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[4]
+}
+
+// Bilateral filter parameters: radius is the window half-size, sigmaSpace
+// controls how fast spatial weight falls off with distance, and sigmaColor
+// controls how fast weight falls off with color difference (the part that
+// keeps edges sharp while smoothing flat regions).
+const (
+	bilateralRadius     = 2
+	bilateralSigmaSpace = 2.0
+	bilateralSigmaColor = 25.0
+)
+
+// bilateralFilter smooths img while preserving edges: each output pixel is
+// a weighted average of its neighborhood, where the weight combines a
+// spatial Gaussian with a Gaussian over color distance so dissimilar
+// neighbors (likely across an edge) contribute little.
+func bilateralFilter(img image.Image) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	out := image.NewRGBA(bounds)
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			cr, cg, cb, ca := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			centerR, centerG, centerB := float64(cr>>8), float64(cg>>8), float64(cb>>8)
+
+			var sumR, sumG, sumB, sumWeight float64
+			for dy := -bilateralRadius; dy <= bilateralRadius; dy++ {
+				for dx := -bilateralRadius; dx <= bilateralRadius; dx++ {
+					sx := clampInt(x+dx, 0, w-1)
+					sy := clampInt(y+dy, 0, h-1)
+					r, g, b, _ := img.At(bounds.Min.X+sx, bounds.Min.Y+sy).RGBA()
+					nr, ng, nb := float64(r>>8), float64(g>>8), float64(b>>8)
+
+					spatialDist := float64(dx*dx + dy*dy)
+					colorDist := (nr-centerR)*(nr-centerR) + (ng-centerG)*(ng-centerG) + (nb-centerB)*(nb-centerB)
+					weight := math.Exp(-spatialDist/(2*bilateralSigmaSpace*bilateralSigmaSpace)) *
+						math.Exp(-colorDist/(2*bilateralSigmaColor*bilateralSigmaColor))
+
+					sumR += nr * weight
+					sumG += ng * weight
+					sumB += nb * weight
+					sumWeight += weight
+				}
+			}
+
+			out.SetRGBA(bounds.Min.X+x, bounds.Min.Y+y, color.RGBA{
+				R: uint8(sumR / sumWeight), G: uint8(sumG / sumWeight), B: uint8(sumB / sumWeight), A: uint8(ca >> 8),
+			})
+		}
+	}
+
+	return out
+}
+
+// grayscaleValues computes the Rec. 601 luma of every pixel in img.
+func grayscaleValues(img image.Image) [][]float64 {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	gray := make([][]float64, h)
+	for y := 0; y < h; y++ {
+		gray[y] = make([]float64, w)
+		for x := 0; x < w; x++ {
+			r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			gray[y][x] = 0.299*float64(r>>8) + 0.587*float64(g>>8) + 0.114*float64(b>>8)
+		}
+	}
+	return gray
+}
+
+// buildIntegralImages returns the summed-area tables of gray and gray^2,
+// each sized (h+1)x(w+1) so integralSum can read any rectangle's sum in
+// O(1) without bounds-checking the top/left edge.
+func buildIntegralImages(gray [][]float64, w, h int) (sum, sumSq [][]float64) {
+	sum = make([][]float64, h+1)
+	sumSq = make([][]float64, h+1)
+	for y := 0; y <= h; y++ {
+		sum[y] = make([]float64, w+1)
+		sumSq[y] = make([]float64, w+1)
+	}
+
+	for y := 0; y < h; y++ {
+		var rowSum, rowSumSq float64
+		for x := 0; x < w; x++ {
+			v := gray[y][x]
+			rowSum += v
+			rowSumSq += v * v
+			sum[y+1][x+1] = sum[y][x+1] + rowSum
+			sumSq[y+1][x+1] = sumSq[y][x+1] + rowSumSq
+		}
+	}
+
+	return sum, sumSq
+}
+
+// integralSum returns the sum of the inclusive rectangle [x0,x1]x[y0,y1]
+// using the summed-area table ii built by buildIntegralImages.
+func integralSum(ii [][]float64, x0, y0, x1, y1 int) float64 {
+	return ii[y1+1][x1+1] - ii[y0][x1+1] - ii[y1+1][x0] + ii[y0][x0]
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestApplyPreprocUnknownStage(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	if _, err := applyPreproc(img, "sauvola,bogus"); err == nil {
+		t.Fatal("expected error for unknown preproc stage")
+	}
+}
+
+func TestApplyPreprocEmptySpecIsNoop(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	out, err := applyPreproc(img, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != image.Image(img) {
+		t.Error("empty spec should return img unchanged")
+	}
+}
+
+// halfToneImage builds a high-contrast image: left half near-black, right
+// half near-white, so binarization should cleanly separate the two sides.
+func halfToneImage(w, h int) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8(20)
+			if x >= w/2 {
+				v = 235
+			}
+			img.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+	return img
+}
+
+func TestOtsuBinarizeSeparatesHalves(t *testing.T) {
+	const w, h = 20, 4
+	out := otsuBinarize(halfToneImage(w, h))
+
+	for y := 0; y < h; y++ {
+		left, _, _, _ := out.At(2, y).RGBA()
+		right, _, _, _ := out.At(w-3, y).RGBA()
+		if left != 0 {
+			t.Errorf("expected dark-side pixel (%d,%d) to binarize to black, got %v", 2, y, left)
+		}
+		if right == 0 {
+			t.Errorf("expected light-side pixel (%d,%d) to binarize to white, got %v", w-3, y, right)
+		}
+	}
+}
+
+func TestSauvolaBinarizeSeparatesHalves(t *testing.T) {
+	const w, h = 40, 40
+	out := sauvolaBinarize(halfToneImage(w, h))
+
+	left, _, _, _ := out.At(5, h/2).RGBA()
+	right, _, _, _ := out.At(w-5, h/2).RGBA()
+	if left != 0 {
+		t.Errorf("expected dark-side pixel to binarize to black, got %v", left)
+	}
+	if right == 0 {
+		t.Errorf("expected light-side pixel to binarize to white, got %v", right)
+	}
+}
+
+func TestMedianFilterRemovesSaltAndPepper(t *testing.T) {
+	const w, h = 9, 9
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{R: 100, G: 100, B: 100, A: 255})
+		}
+	}
+	// Single bright outlier pixel in the middle of an otherwise flat field.
+	img.Set(4, 4, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+
+	out := medianFilter(img)
+	r, g, b, _ := out.At(4, 4).RGBA()
+	if uint8(r>>8) != 100 || uint8(g>>8) != 100 || uint8(b>>8) != 100 {
+		t.Errorf("expected the salt-and-pepper outlier to be smoothed to 100,100,100, got %d,%d,%d", r>>8, g>>8, b>>8)
+	}
+}
+
+func TestBilateralFilterPreservesFlatRegion(t *testing.T) {
+	const w, h = 7, 7
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{R: 50, G: 60, B: 70, A: 255})
+		}
+	}
+
+	out := bilateralFilter(img)
+	r, g, b, _ := out.At(3, 3).RGBA()
+	// Allow +/-1 for integer truncation in the weighted average.
+	if absDiff(int(r>>8), 50) > 1 || absDiff(int(g>>8), 60) > 1 || absDiff(int(b>>8), 70) > 1 {
+		t.Errorf("bilateral filter should leave a perfectly flat region unchanged, got %d,%d,%d", r>>8, g>>8, b>>8)
+	}
+}
+
+func absDiff(a, b int) int {
+	if a < b {
+		return b - a
+	}
+	return a - b
+}
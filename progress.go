@@ -2,10 +2,12 @@ package main
 
 import (
 	"fmt"
+	"sync"
 	"time"
 )
 
 type ProgressTracker struct {
+	mu         sync.Mutex
 	total      int
 	processed  int
 	quiet      bool
@@ -22,11 +24,16 @@ func NewProgressTracker(total int, quiet bool) *ProgressTracker {
 	}
 }
 
+// Update is safe for concurrent callers (createColorGrid and the banded
+// findOptimalBlocks both update the same tracker from multiple goroutines).
 func (p *ProgressTracker) Update(increment int) {
 	if p.quiet {
 		return
 	}
 
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	p.processed += increment
 
 	if time.Since(p.lastUpdate) < 100*time.Millisecond && p.processed < p.total {
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sort"
+)
+
+// Quantizer reduces an image to at most n palette colors, returning the
+// resulting paletted image and the palette itself (so callers can report
+// its size without re-deriving it from the image).
+type Quantizer interface {
+	Quantize(img image.Image, n int) (*image.Paletted, color.Palette)
+}
+
+// NewQuantizer returns the Quantizer registered under name, defaulting to
+// median-cut (the only algorithm implemented so far) when name is empty. It
+// errors on any other unrecognized name rather than silently falling back,
+// so --quantizer octree doesn't quietly run median-cut instead.
+func NewQuantizer(name string) (Quantizer, error) {
+	switch name {
+	case "median-cut", "":
+		return medianCutQuantizer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown --quantizer %q (want median-cut)", name)
+	}
+}
+
+// medianCutQuantizer implements the classic median-cut algorithm: start
+// with one box containing every pixel color, repeatedly split the box with
+// the largest range on its longest axis at the median, until n boxes
+// exist. Each box's palette entry is the mean of the colors it contains.
+type medianCutQuantizer struct{}
+
+func (medianCutQuantizer) Quantize(img image.Image, n int) (*image.Paletted, color.Palette) {
+	if n < 1 {
+		n = 1
+	}
+
+	bounds := img.Bounds()
+	colors := make([]color.RGBA, 0, bounds.Dx()*bounds.Dy())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			colors = append(colors, color.RGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: uint8(a >> 8)})
+		}
+	}
+
+	boxes := []colorBox{{colors: colors}}
+	for len(boxes) < n {
+		splitIdx := -1
+		var splitRange uint8
+		for i, b := range boxes {
+			if len(b.colors) < 2 {
+				continue
+			}
+			if _, r := b.widestAxis(); splitIdx == -1 || r > splitRange {
+				splitIdx, splitRange = i, r
+			}
+		}
+		if splitIdx == -1 {
+			break // every remaining box is a single color; can't split further
+		}
+
+		axis, _ := boxes[splitIdx].widestAxis()
+		boxes[splitIdx].splitAt(axis)
+		boxes = append(boxes, *boxes[splitIdx].right)
+	}
+
+	palette := make(color.Palette, 0, len(boxes))
+	for _, b := range boxes {
+		palette = append(palette, b.mean())
+	}
+
+	var paletted *image.Paletted
+	if ditherEnabled {
+		paletted = floydSteinbergDither(img, palette)
+	} else {
+		paletted = image.NewPaletted(bounds, palette)
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				r, g, b, a := img.At(x, y).RGBA()
+				c := color.RGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: uint8(a >> 8)}
+				paletted.SetColorIndex(x, y, nearestPaletteIndex(c, palette))
+			}
+		}
+	}
+
+	return paletted, palette
+}
+
+// colorBox is one median-cut bucket: the set of pixel colors currently
+// assigned to it. right is set by splitAt as a convenience so the caller
+// doesn't need a second return value threaded through the boxes slice.
+type colorBox struct {
+	colors []color.RGBA
+	right  *colorBox
+}
+
+// widestAxis returns which channel (0=R, 1=G, 2=B, 3=A) has the largest
+// value range in this box, and that range.
+func (b colorBox) widestAxis() (axis int, rng uint8) {
+	min := [4]uint8{255, 255, 255, 255}
+	max := [4]uint8{0, 0, 0, 0}
+	for _, c := range b.colors {
+		v := [4]uint8{c.R, c.G, c.B, c.A}
+		for i, x := range v {
+			if x < min[i] {
+				min[i] = x
+			}
+			if x > max[i] {
+				max[i] = x
+			}
+		}
+	}
+	for i := 0; i < 4; i++ {
+		if r := max[i] - min[i]; i == 0 || r > rng {
+			axis, rng = i, r
+		}
+	}
+	return axis, rng
+}
+
+// splitAt sorts the box's colors along axis and splits it in half at the
+// median, keeping the lower half in b and stashing the upper half in b.right.
+func (b *colorBox) splitAt(axis int) {
+	sort.Slice(b.colors, func(i, j int) bool {
+		return channelValue(b.colors[i], axis) < channelValue(b.colors[j], axis)
+	})
+	mid := len(b.colors) / 2
+	b.right = &colorBox{colors: b.colors[mid:]}
+	b.colors = b.colors[:mid]
+}
+
+func channelValue(c color.RGBA, axis int) uint8 {
+	switch axis {
+	case 0:
+		return c.R
+	case 1:
+		return c.G
+	case 2:
+		return c.B
+	default:
+		return c.A
+	}
+}
+
+// mean returns the average color of every pixel in the box; this becomes
+// the box's palette entry.
+func (b colorBox) mean() color.RGBA {
+	if len(b.colors) == 0 {
+		return color.RGBA{}
+	}
+	var sr, sg, sb, sa uint64
+	for _, c := range b.colors {
+		sr += uint64(c.R)
+		sg += uint64(c.G)
+		sb += uint64(c.B)
+		sa += uint64(c.A)
+	}
+	n := uint64(len(b.colors))
+	return color.RGBA{R: uint8(sr / n), G: uint8(sg / n), B: uint8(sb / n), A: uint8(sa / n)}
+}
+
+// alphaWeight scales the alpha channel's contribution to nearestPaletteIndex
+// relative to R/G/B, so that fully- or mostly-transparent pixels match
+// against a transparent palette entry rather than whichever opaque color
+// happens to be closest in RGB alone.
+const alphaWeight = 2.0
+
+// nearestPaletteIndex finds the palette entry closest to c under squared
+// Euclidean distance over R, G, B and alpha, with alpha weighted by
+// alphaWeight so transparent and opaque pixels don't get confused for one
+// another.
+func nearestPaletteIndex(c color.RGBA, palette color.Palette) uint8 {
+	best := 0
+	bestDist := -1.0
+	for i, p := range palette {
+		pr, pg, pb, pa := p.RGBA()
+		entry := color.RGBA{R: uint8(pr >> 8), G: uint8(pg >> 8), B: uint8(pb >> 8), A: uint8(pa >> 8)}
+
+		dr := float64(int(c.R) - int(entry.R))
+		dg := float64(int(c.G) - int(entry.G))
+		db := float64(int(c.B) - int(entry.B))
+		da := float64(int(c.A) - int(entry.A))
+		dist := dr*dr + dg*dg + db*db + alphaWeight*da*da
+
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+	return uint8(best)
+}
+
+// floydSteinbergDither remaps img onto palette using Floyd-Steinberg error
+// diffusion: each pixel's quantization error (original minus chosen palette
+// color) is spread to its right and below neighbors using the standard
+// 7/16, 3/16, 5/16, 1/16 weights. Alpha is matched but not diffused, since
+// diffusing it would bleed opacity across hard transparency boundaries.
+//
+// Dithering trades block-merging for visual fidelity: it breaks up the long
+// same-color runs findOptimalBlocks relies on, so it defaults to off and is
+// meant for callers who want a more faithful preview over a small SVG.
+func floydSteinbergDither(img image.Image, palette color.Palette) *image.Paletted {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	paletted := image.NewPaletted(bounds, palette)
+
+	errR := make([][]float64, h)
+	errG := make([][]float64, h)
+	errB := make([][]float64, h)
+	for y := range errR {
+		errR[y] = make([]float64, w)
+		errG[y] = make([]float64, w)
+		errB[y] = make([]float64, w)
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			r, g, b, a := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			oldR := clampFloat(float64(uint8(r>>8))+errR[y][x], 0, 255)
+			oldG := clampFloat(float64(uint8(g>>8))+errG[y][x], 0, 255)
+			oldB := clampFloat(float64(uint8(b>>8))+errB[y][x], 0, 255)
+
+			c := color.RGBA{R: uint8(oldR), G: uint8(oldG), B: uint8(oldB), A: uint8(a >> 8)}
+			idx := nearestPaletteIndex(c, palette)
+			paletted.SetColorIndex(bounds.Min.X+x, bounds.Min.Y+y, idx)
+
+			chosen := palette[idx]
+			cr, cg, cb, _ := chosen.RGBA()
+			diffR := oldR - float64(uint8(cr>>8))
+			diffG := oldG - float64(uint8(cg>>8))
+			diffB := oldB - float64(uint8(cb>>8))
+
+			distribute := func(errGrid [][]float64, dx, dy int, weight float64, diff float64) {
+				nx, ny := x+dx, y+dy
+				if nx < 0 || nx >= w || ny < 0 || ny >= h {
+					return
+				}
+				errGrid[ny][nx] += diff * weight
+			}
+
+			distribute(errR, 1, 0, 7.0/16, diffR)
+			distribute(errR, -1, 1, 3.0/16, diffR)
+			distribute(errR, 0, 1, 5.0/16, diffR)
+			distribute(errR, 1, 1, 1.0/16, diffR)
+
+			distribute(errG, 1, 0, 7.0/16, diffG)
+			distribute(errG, -1, 1, 3.0/16, diffG)
+			distribute(errG, 0, 1, 5.0/16, diffG)
+			distribute(errG, 1, 1, 1.0/16, diffG)
+
+			distribute(errB, 1, 0, 7.0/16, diffB)
+			distribute(errB, -1, 1, 3.0/16, diffB)
+			distribute(errB, 0, 1, 5.0/16, diffB)
+			distribute(errB, 1, 1, 1.0/16, diffB)
+		}
+	}
+
+	return paletted
+}
+
+func clampFloat(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// countUniqueColors tallies how many distinct palette indices paletted
+// actually uses, which can be smaller than len(paletted.Palette) when some
+// median-cut boxes end up unused after remapping.
+func countUniqueColors(paletted *image.Paletted) int {
+	seen := make(map[uint8]bool)
+	for _, idx := range paletted.Pix {
+		seen[idx] = true
+	}
+	return len(seen)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestNewQuantizerUnknown(t *testing.T) {
+	if _, err := NewQuantizer("octree"); err == nil {
+		t.Fatal("expected error for unknown quantizer name")
+	}
+	if q, err := NewQuantizer(""); err != nil {
+		t.Fatalf("empty name should default to median-cut: %v", err)
+	} else if _, ok := q.(medianCutQuantizer); !ok {
+		t.Fatalf("expected medianCutQuantizer, got %T", q)
+	}
+}
+
+// twoColorImage builds a 4x1 image split evenly between two solid colors,
+// so a correct median-cut quantizer should recover exactly those two
+// colors as its 2-entry palette.
+func twoColorImage() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 1))
+	img.Set(0, 0, color.NRGBA{R: 255, G: 0, B: 0, A: 255})
+	img.Set(1, 0, color.NRGBA{R: 255, G: 0, B: 0, A: 255})
+	img.Set(2, 0, color.NRGBA{R: 0, G: 0, B: 255, A: 255})
+	img.Set(3, 0, color.NRGBA{R: 0, G: 0, B: 255, A: 255})
+	return img
+}
+
+func TestMedianCutQuantizeTwoColors(t *testing.T) {
+	q := medianCutQuantizer{}
+	paletted, palette := q.Quantize(twoColorImage(), 2)
+
+	if len(palette) != 2 {
+		t.Fatalf("expected a 2-entry palette, got %d", len(palette))
+	}
+
+	seen := map[color.RGBA]bool{}
+	for _, c := range palette {
+		r, g, b, a := c.RGBA()
+		seen[color.RGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: uint8(a >> 8)}] = true
+	}
+	want := []color.RGBA{{R: 255, A: 255}, {B: 255, A: 255}}
+	for _, c := range want {
+		if !seen[c] {
+			t.Errorf("palette missing expected color %+v, got %+v", c, palette)
+		}
+	}
+
+	if paletted.Bounds() != twoColorImage().Bounds() {
+		t.Errorf("paletted image bounds %v don't match source", paletted.Bounds())
+	}
+}
+
+func TestMedianCutQuantizeSingleColor(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 3))
+	want := color.RGBA{R: 10, G: 20, B: 30, A: 255}
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			img.Set(x, y, want)
+		}
+	}
+
+	q := medianCutQuantizer{}
+	_, palette := q.Quantize(img, 4)
+
+	// A solid-color image has nothing to split on, so every resulting box
+	// (even split n-ways) keeps the same mean color.
+	for i, c := range palette {
+		r, g, b, a := c.RGBA()
+		got := color.RGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: uint8(a >> 8)}
+		if got != want {
+			t.Errorf("palette[%d] = %+v, want every entry to be %+v", i, got, want)
+		}
+	}
+}
+
+func TestNearestPaletteIndex(t *testing.T) {
+	palette := color.Palette{
+		color.RGBA{R: 0, G: 0, B: 0, A: 255},
+		color.RGBA{R: 255, G: 255, B: 255, A: 255},
+		color.RGBA{R: 0, G: 0, B: 0, A: 0},
+	}
+
+	if idx := nearestPaletteIndex(color.RGBA{R: 10, G: 5, B: 0, A: 255}, palette); idx != 0 {
+		t.Errorf("near-black opaque pixel should match palette[0], got %d", idx)
+	}
+	if idx := nearestPaletteIndex(color.RGBA{R: 250, G: 250, B: 250, A: 255}, palette); idx != 1 {
+		t.Errorf("near-white opaque pixel should match palette[1], got %d", idx)
+	}
+	if idx := nearestPaletteIndex(color.RGBA{R: 0, G: 0, B: 0, A: 0}, palette); idx != 2 {
+		t.Errorf("transparent pixel should match the transparent palette entry, got %d", idx)
+	}
+}
+
+func TestFloydSteinbergDitherUsesOnlyPaletteColors(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x * 30), G: uint8(y * 30), B: 128, A: 255})
+		}
+	}
+	palette := color.Palette{
+		color.RGBA{R: 0, G: 0, B: 0, A: 255},
+		color.RGBA{R: 255, G: 255, B: 255, A: 255},
+	}
+
+	paletted := floydSteinbergDither(img, palette)
+	for _, idx := range paletted.Pix {
+		if int(idx) >= len(palette) {
+			t.Fatalf("dither produced out-of-range palette index %d", idx)
+		}
+	}
+}
+
+func TestCountUniqueColors(t *testing.T) {
+	bounds := image.Rect(0, 0, 2, 2)
+	palette := color.Palette{color.RGBA{R: 1, A: 255}, color.RGBA{R: 2, A: 255}, color.RGBA{R: 3, A: 255}}
+	paletted := image.NewPaletted(bounds, palette)
+	paletted.SetColorIndex(0, 0, 0)
+	paletted.SetColorIndex(1, 0, 0)
+	paletted.SetColorIndex(0, 1, 1)
+	paletted.SetColorIndex(1, 1, 1)
+
+	if got := countUniqueColors(paletted); got != 2 {
+		t.Errorf("expected 2 unique colors actually used, got %d", got)
+	}
+}
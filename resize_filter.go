@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+)
+
+const (
+	filterNearest  = "nearest"
+	filterBilinear = "bilinear"
+	filterBicubic  = "bicubic"
+	filterLanczos3 = "lanczos3"
+)
+
+// filterKernel returns the 1-D resampling kernel and its support radius (in
+// source-pixel units) for name. It errors on any unrecognized name rather
+// than silently falling back to a box kernel, so --filter bogus doesn't
+// quietly resize with the wrong filter (mirrors NewQuantizer's handling of
+// --quantizer). name is expected to already have filterNearest/"" filtered
+// out by the caller, since those never reach a kernel at all.
+func filterKernel(name string) (kernel func(x float64) float64, radius float64, err error) {
+	switch name {
+	case filterBilinear:
+		return triangleKernel, 1, nil
+	case filterBicubic:
+		return mitchellNetravaliKernel, 2, nil
+	case filterLanczos3:
+		return lanczos3Kernel, 3, nil
+	default:
+		return nil, 0, fmt.Errorf("unknown --filter %q (want nearest|bilinear|bicubic|lanczos3)", name)
+	}
+}
+
+func boxKernel(x float64) float64 {
+	if x < -0.5 || x >= 0.5 {
+		return 0
+	}
+	return 1
+}
+
+func triangleKernel(x float64) float64 {
+	x = math.Abs(x)
+	if x >= 1 {
+		return 0
+	}
+	return 1 - x
+}
+
+// mitchellNetravaliKernel is the Mitchell-Netravali bicubic filter with the
+// commonly recommended B=1/3, C=1/3.
+func mitchellNetravaliKernel(x float64) float64 {
+	const b = 1.0 / 3
+	const c = 1.0 / 3
+	x = math.Abs(x)
+	switch {
+	case x < 1:
+		return ((12-9*b-6*c)*x*x*x + (-18+12*b+6*c)*x*x + (6 - 2*b)) / 6
+	case x < 2:
+		return ((-b-6*c)*x*x*x + (6*b+30*c)*x*x + (-12*b-48*c)*x + (8*b + 24*c)) / 6
+	default:
+		return 0
+	}
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	x *= math.Pi
+	return math.Sin(x) / x
+}
+
+func lanczos3Kernel(x float64) float64 {
+	if x <= -3 || x >= 3 {
+		return 0
+	}
+	return sinc(x) * sinc(x/3)
+}
+
+// resizeWithFilter resamples img to newW x newH using the named filter's
+// separable 1-D kernel: a horizontal pass followed by a vertical pass, each
+// into an intermediate *image.RGBA64 to avoid precision loss between
+// passes. Colors are resampled premultiplied and un-premultiplied at the
+// end so transparent edges don't pick up dark halos from the fully
+// transparent pixels around them. Kernel weights are normalized per output
+// pixel, which also handles edge clipping.
+func resizeWithFilter(img image.Image, newW, newH int, filterName string) (image.Image, error) {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	kernel, radius, err := filterKernel(filterName)
+	if err != nil {
+		return nil, err
+	}
+
+	premult := image.NewRGBA64(image.Rect(0, 0, srcW, srcH))
+	for y := 0; y < srcH; y++ {
+		for x := 0; x < srcW; x++ {
+			r, g, b, a := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			premult.SetRGBA64(x, y, color.RGBA64{R: uint16(r), G: uint16(g), B: uint16(b), A: uint16(a)})
+		}
+	}
+
+	horiz := resamplePass(premult, srcW, srcH, newW, srcH, kernel, radius, true)
+	vert := resamplePass(horiz, newW, srcH, newW, newH, kernel, radius, false)
+
+	out := image.NewRGBA64(image.Rect(0, 0, newW, newH))
+	for y := 0; y < newH; y++ {
+		for x := 0; x < newW; x++ {
+			out.SetRGBA64(x, y, unpremultiply(vert.RGBA64At(x, y)))
+		}
+	}
+	return out, nil
+}
+
+// resamplePass applies kernel along one axis of src (horizontal when
+// horizontal is true, vertical otherwise), producing a dstW x dstH image.
+// The non-resized axis is left untouched.
+func resamplePass(src *image.RGBA64, srcW, srcH, dstW, dstH int, kernel func(float64) float64, radius float64, horizontal bool) *image.RGBA64 {
+	dst := image.NewRGBA64(image.Rect(0, 0, dstW, dstH))
+
+	resampleLine := func(out, in int, scale float64) (lo, hi int, center float64) {
+		center = (float64(out)+0.5)*scale - 0.5
+		return int(math.Floor(center - radius)), int(math.Ceil(center + radius)), center
+	}
+
+	if horizontal {
+		scale := float64(srcW) / float64(dstW)
+		for y := 0; y < srcH; y++ {
+			for x := 0; x < dstW; x++ {
+				lo, hi, center := resampleLine(x, srcW, scale)
+				var sr, sg, sb, sa, sw float64
+				for sx := lo; sx <= hi; sx++ {
+					if sx < 0 || sx >= srcW {
+						continue
+					}
+					w := kernel(float64(sx) - center)
+					if w == 0 {
+						continue
+					}
+					p := src.RGBA64At(sx, y)
+					sr += float64(p.R) * w
+					sg += float64(p.G) * w
+					sb += float64(p.B) * w
+					sa += float64(p.A) * w
+					sw += w
+				}
+				dst.SetRGBA64(x, y, weightedColor(sr, sg, sb, sa, sw))
+			}
+		}
+		return dst
+	}
+
+	scale := float64(srcH) / float64(dstH)
+	for x := 0; x < srcW; x++ {
+		for y := 0; y < dstH; y++ {
+			lo, hi, center := resampleLine(y, srcH, scale)
+			var sr, sg, sb, sa, sw float64
+			for sy := lo; sy <= hi; sy++ {
+				if sy < 0 || sy >= srcH {
+					continue
+				}
+				w := kernel(float64(sy) - center)
+				if w == 0 {
+					continue
+				}
+				p := src.RGBA64At(x, sy)
+				sr += float64(p.R) * w
+				sg += float64(p.G) * w
+				sb += float64(p.B) * w
+				sa += float64(p.A) * w
+				sw += w
+			}
+			dst.SetRGBA64(x, y, weightedColor(sr, sg, sb, sa, sw))
+		}
+	}
+	return dst
+}
+
+func weightedColor(sr, sg, sb, sa, sw float64) color.RGBA64 {
+	if sw == 0 {
+		return color.RGBA64{}
+	}
+	return color.RGBA64{
+		R: clampUint16(sr / sw),
+		G: clampUint16(sg / sw),
+		B: clampUint16(sb / sw),
+		A: clampUint16(sa / sw),
+	}
+}
+
+func unpremultiply(p color.RGBA64) color.RGBA64 {
+	if p.A == 0 {
+		return color.RGBA64{}
+	}
+	scale := 65535.0 / float64(p.A)
+	return color.RGBA64{
+		R: clampUint16(float64(p.R) * scale),
+		G: clampUint16(float64(p.G) * scale),
+		B: clampUint16(float64(p.B) * scale),
+		A: p.A,
+	}
+}
+
+func clampUint16(v float64) uint16 {
+	if v < 0 {
+		return 0
+	}
+	if v > 65535 {
+		return 65535
+	}
+	return uint16(v + 0.5)
+}
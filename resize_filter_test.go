@@ -0,0 +1,84 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestFilterKernelUnknown(t *testing.T) {
+	if _, _, err := filterKernel("bogus"); err == nil {
+		t.Fatal("expected error for unknown filter name")
+	}
+}
+
+func TestFilterKernelsPeakAtZero(t *testing.T) {
+	for _, name := range []string{filterBilinear, filterBicubic, filterLanczos3} {
+		kernel, radius, err := filterKernel(name)
+		if err != nil {
+			t.Fatalf("filterKernel(%q): %v", name, err)
+		}
+		at0 := kernel(0)
+		if got := kernel(radius / 2); got > at0 {
+			t.Errorf("%s kernel should peak at x=0 (%v), but x=radius/2 was larger (%v)", name, at0, got)
+		}
+		if got := kernel(radius + 1); got != 0 {
+			t.Errorf("%s kernel outside its support radius should be 0, got %v", name, got)
+		}
+	}
+}
+
+func TestBoxKernelSupport(t *testing.T) {
+	if boxKernel(0) != 1 {
+		t.Error("boxKernel(0) should be 1")
+	}
+	if boxKernel(0.6) != 0 {
+		t.Error("boxKernel outside +/-0.5 should be 0")
+	}
+}
+
+// checkerImage builds a w x h image alternating pure red and pure blue
+// pixels, a stress case for resampling: any blur averages toward purple.
+func checkerImage(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if (x+y)%2 == 0 {
+				img.Set(x, y, color.NRGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.NRGBA{B: 255, A: 255})
+			}
+		}
+	}
+	return img
+}
+
+func TestResizeWithFilterUnknownFilter(t *testing.T) {
+	if _, err := resizeWithFilter(checkerImage(8, 8), 4, 4, "bogus"); err == nil {
+		t.Fatal("expected error for unknown filter name")
+	}
+}
+
+func TestResizeWithFilterProducesRequestedDimensions(t *testing.T) {
+	for _, name := range []string{filterBilinear, filterBicubic, filterLanczos3} {
+		out, err := resizeWithFilter(checkerImage(16, 16), 8, 4, name)
+		if err != nil {
+			t.Fatalf("resizeWithFilter(%s): %v", name, err)
+		}
+		b := out.Bounds()
+		if b.Dx() != 8 || b.Dy() != 4 {
+			t.Errorf("%s: got %dx%d, want 8x4", name, b.Dx(), b.Dy())
+		}
+	}
+}
+
+func TestResizeWithFilterOpaqueStaysOpaque(t *testing.T) {
+	out, err := resizeWithFilter(checkerImage(8, 8), 4, 4, filterBilinear)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, _, a := out.At(0, 0).RGBA()
+	if a != 0xFFFF {
+		t.Errorf("resizing a fully-opaque source should stay fully opaque, got alpha %d", a)
+	}
+}
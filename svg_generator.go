@@ -1,12 +1,27 @@
 package main
 
 import (
+	"compress/gzip"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 )
 
+// classThreshold is the largest unique-color count "class" mode will still
+// assign short class names to; beyond this it falls back to inline fills
+// since the <style> block would cost more than it saves.
+const classThreshold = 256
+
 func generateSVGFile(blocks []Block, width, height int, outputPath string) error {
+	switch svgStyle {
+	case "", "inline", "class", "use":
+	default:
+		return fmt.Errorf("unknown --svg-style %q (want inline|class|use)", svgStyle)
+	}
+
 	if !quiet {
 		fmt.Printf("Writing SVG file...\n")
 	}
@@ -17,29 +32,75 @@ func generateSVGFile(blocks []Block, width, height int, outputPath string) error
 	}
 	defer file.Close()
 
-	writer := NewSVGWriter(file, width, height)
-	return writer.WriteBlocks(blocks)
+	var w io.Writer = file
+	var gz *gzip.Writer
+	if strings.ToLower(filepath.Ext(outputPath)) == ".svgz" {
+		gz = gzip.NewWriter(file)
+		w = gz
+	}
+
+	writer := NewSVGWriterStyle(w, width, height, svgStyle)
+	werr := writer.WriteBlocks(blocks)
+
+	if gz != nil {
+		if cerr := gz.Close(); werr == nil {
+			werr = cerr
+		}
+	}
+
+	return werr
 }
 
 type SVGWriter struct {
-	file   *os.File
-	width  int
-	height int
+	w       io.Writer
+	width   int
+	height  int
+	style   string // "inline" (default), "class", or "use"
+	classOf map[string]string
+	order   []string
+}
+
+// NewSVGWriter builds a writer using the inline emission mode. Use
+// NewSVGWriterStyle to request "class" or "use" mode.
+func NewSVGWriter(w io.Writer, width, height int) *SVGWriter {
+	return NewSVGWriterStyle(w, width, height, "inline")
 }
 
-func NewSVGWriter(file *os.File, width, height int) *SVGWriter {
+func NewSVGWriterStyle(w io.Writer, width, height int, style string) *SVGWriter {
+	if style == "" {
+		style = "inline"
+	}
 	return &SVGWriter{
-		file:   file,
+		w:      w,
 		width:  width,
 		height: height,
+		style:  style,
 	}
 }
 
 func (w *SVGWriter) WriteBlocks(blocks []Block) error {
+	if w.style != "inline" {
+		w.indexPalette(blocks)
+		if len(w.order) > classThreshold {
+			w.style = "inline" // too many colors for class/use to pay off
+		}
+	}
+
 	if err := w.writeHeader(); err != nil {
 		return err
 	}
 
+	switch w.style {
+	case "class":
+		if err := w.writeStyleBlock(); err != nil {
+			return err
+		}
+	case "use":
+		if err := w.writeSymbols(); err != nil {
+			return err
+		}
+	}
+
 	progress := NewProgressTracker(len(blocks), quiet)
 	defer progress.Finish()
 
@@ -56,12 +117,65 @@ func (w *SVGWriter) WriteBlocks(blocks []Block) error {
 	return w.writeFooter()
 }
 
+// indexPalette tallies each block's unique (opaque) color and assigns it a
+// short class/symbol name in first-seen order.
+func (w *SVGWriter) indexPalette(blocks []Block) {
+	w.classOf = make(map[string]string)
+	for _, b := range blocks {
+		if b.A == 0 {
+			continue
+		}
+		color := w.optimizeColor(b.R, b.G, b.B)
+		if _, ok := w.classOf[color]; ok {
+			continue
+		}
+		w.classOf[color] = classNameFor(len(w.order))
+		w.order = append(w.order, color)
+	}
+}
+
+// classNameFor maps a palette index to a short lowercase-letter name:
+// 0->"a", 1->"b", ..., 25->"z", 26->"aa", ...
+func classNameFor(i int) string {
+	name := ""
+	for {
+		name = string(rune('a'+i%26)) + name
+		i = i/26 - 1
+		if i < 0 {
+			break
+		}
+	}
+	return name
+}
+
 func (w *SVGWriter) writeHeader() error {
 	// Ultra-compact header with minimal whitespace
-	header := `<?xml version="1.0" encoding="UTF-8"?><svg width="` + 
-		strconv.Itoa(w.width) + `" height="` + strconv.Itoa(w.height) + 
+	header := `<?xml version="1.0" encoding="UTF-8"?><svg width="` +
+		strconv.Itoa(w.width) + `" height="` + strconv.Itoa(w.height) +
 		`" xmlns="http://www.w3.org/2000/svg">`
-	_, err := w.file.WriteString(header)
+	_, err := io.WriteString(w.w, header)
+	return err
+}
+
+func (w *SVGWriter) writeStyleBlock() error {
+	var sb strings.Builder
+	sb.WriteString(`<style>`)
+	for _, color := range w.order {
+		sb.WriteString(`.` + w.classOf[color] + `{fill:` + color + `}`)
+	}
+	sb.WriteString(`</style>`)
+	_, err := io.WriteString(w.w, sb.String())
+	return err
+}
+
+func (w *SVGWriter) writeSymbols() error {
+	var sb strings.Builder
+	sb.WriteString(`<defs>`)
+	for _, color := range w.order {
+		sb.WriteString(`<symbol id="` + w.classOf[color] + `" viewBox="0 0 1 1"><rect width="1" height="1" fill="` + color + `"/></symbol>`)
+	}
+	sb.WriteString(`</defs>`)
+	_, err := io.WriteString(w.w, sb.String())
 	return err
 }
 
@@ -71,26 +185,34 @@ func (w *SVGWriter) writeBlock(block Block) error {
 		return nil
 	}
 
-	// Optimize color format
-	color := w.optimizeColor(block.R, block.G, block.B)
-	
-	rect := `<rect x="` + strconv.Itoa(block.X) + `" y="` + strconv.Itoa(block.Y) + 
-		`" width="` + strconv.Itoa(block.Width) + `" height="` + strconv.Itoa(block.Height) + 
-		`" fill="` + color
-	
-	// Add opacity if not fully opaque
+	opacity := ""
 	if block.A < 255 {
-		opacity := float64(block.A) / 255.0
-		rect += `" fill-opacity="` + strconv.FormatFloat(opacity, 'f', 3, 64)
+		opacity = ` fill-opacity="` + strconv.FormatFloat(float64(block.A)/255.0, 'f', 3, 64) + `"`
 	}
-	
-	rect += `"/>`
-	_, err := w.file.WriteString(rect)
+
+	var rect string
+	switch w.style {
+	case "class":
+		color := w.optimizeColor(block.R, block.G, block.B)
+		rect = `<rect class="` + w.classOf[color] + `" x="` + strconv.Itoa(block.X) + `" y="` + strconv.Itoa(block.Y) +
+			`" width="` + strconv.Itoa(block.Width) + `" height="` + strconv.Itoa(block.Height) + `"` + opacity + `/>`
+	case "use":
+		color := w.optimizeColor(block.R, block.G, block.B)
+		rect = `<use href="#` + w.classOf[color] + `" x="` + strconv.Itoa(block.X) + `" y="` + strconv.Itoa(block.Y) +
+			`" width="` + strconv.Itoa(block.Width) + `" height="` + strconv.Itoa(block.Height) + `"` + opacity + `/>`
+	default:
+		color := w.optimizeColor(block.R, block.G, block.B)
+		rect = `<rect x="` + strconv.Itoa(block.X) + `" y="` + strconv.Itoa(block.Y) +
+			`" width="` + strconv.Itoa(block.Width) + `" height="` + strconv.Itoa(block.Height) +
+			`" fill="` + color + `"` + opacity + `/>`
+	}
+
+	_, err := io.WriteString(w.w, rect)
 	return err
 }
 
 func (w *SVGWriter) writeFooter() error {
-	_, err := w.file.WriteString(`</svg>`)
+	_, err := io.WriteString(w.w, `</svg>`)
 	return err
 }
 
@@ -100,7 +222,7 @@ func (w *SVGWriter) optimizeColor(r, g, b uint8) string {
 	if r>>4 == r&0x0F && g>>4 == g&0x0F && b>>4 == b&0x0F {
 		return "#" + string(hexChar(r>>4)) + string(hexChar(g>>4)) + string(hexChar(b>>4))
 	}
-	
+
 	// Otherwise use full hex
 	return "#" + string(hexChar(r>>4)) + string(hexChar(r&0x0F)) +
 		string(hexChar(g>>4)) + string(hexChar(g&0x0F)) +
@@ -112,4 +234,4 @@ func hexChar(b byte) byte {
 		return '0' + b
 	}
 	return 'a' + (b - 10)
-}
\ No newline at end of file
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGenerateSVGFileUnknownStyle(t *testing.T) {
+	prevStyle := svgStyle
+	svgStyle = "bogus"
+	defer func() { svgStyle = prevStyle }()
+
+	if err := generateSVGFile(nil, 10, 10, t.TempDir()+"/out.svg"); err == nil {
+		t.Fatal("expected error for unknown --svg-style")
+	}
+}
+
+func TestClassNameFor(t *testing.T) {
+	cases := map[int]string{0: "a", 1: "b", 25: "z", 26: "aa", 27: "ab", 51: "az", 52: "ba"}
+	for i, want := range cases {
+		if got := classNameFor(i); got != want {
+			t.Errorf("classNameFor(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func testBlocks() []Block {
+	return []Block{
+		{X: 0, Y: 0, Width: 1, Height: 1, R: 255, G: 0, B: 0, A: 255},
+		{X: 1, Y: 0, Width: 1, Height: 1, R: 0, G: 255, B: 0, A: 255},
+		{X: 2, Y: 0, Width: 1, Height: 1, R: 255, G: 0, B: 0, A: 255}, // repeats the first color
+		{X: 3, Y: 0, Width: 1, Height: 1, R: 0, G: 0, B: 0, A: 0},     // fully transparent, should be skipped
+	}
+}
+
+func TestSVGWriterInlineMode(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewSVGWriter(&buf, 4, 1)
+	if err := w.WriteBlocks(testBlocks()); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if strings.Count(out, "<rect") != 3 {
+		t.Errorf("expected 3 rects (transparent block skipped), got output: %s", out)
+	}
+	if !strings.Contains(out, `fill="#f00"`) || !strings.Contains(out, `fill="#0f0"`) {
+		t.Errorf("inline mode should emit fill attributes directly on each rect, got: %s", out)
+	}
+	if strings.Contains(out, "<style>") || strings.Contains(out, "<defs>") {
+		t.Errorf("inline mode should not emit a style block or symbol defs, got: %s", out)
+	}
+}
+
+func TestSVGWriterClassMode(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewSVGWriterStyle(&buf, 4, 1, "class")
+	if err := w.WriteBlocks(testBlocks()); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "<style>") {
+		t.Fatalf("class mode should emit a <style> block, got: %s", out)
+	}
+	// Two distinct opaque colors were indexed, so the style block should
+	// define exactly two classes, and the two same-colored blocks should
+	// share one class name.
+	if strings.Count(out, ".a{fill:") != 1 || strings.Count(out, ".b{fill:") != 1 {
+		t.Errorf("expected exactly two class definitions (a, b), got: %s", out)
+	}
+	if strings.Count(out, `class="a"`) != 2 {
+		t.Errorf("expected the two red blocks to share class \"a\", got: %s", out)
+	}
+}
+
+func TestSVGWriterUseMode(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewSVGWriterStyle(&buf, 4, 1, "use")
+	if err := w.WriteBlocks(testBlocks()); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "<defs>") || !strings.Contains(out, "<symbol") {
+		t.Fatalf("use mode should emit <defs>/<symbol> entries, got: %s", out)
+	}
+	if strings.Count(out, "<use href=") != 3 {
+		t.Errorf("expected 3 <use> references (transparent block skipped), got: %s", out)
+	}
+}
+
+func TestSVGWriterClassModeFallsBackPastThreshold(t *testing.T) {
+	blocks := make([]Block, classThreshold+1)
+	for i := range blocks {
+		blocks[i] = Block{X: i, Y: 0, Width: 1, Height: 1, R: uint8(i), G: uint8(i / 2), B: uint8(i / 3), A: 255}
+	}
+
+	var buf bytes.Buffer
+	w := NewSVGWriterStyle(&buf, len(blocks), 1, "class")
+	if err := w.WriteBlocks(blocks); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "<style>") {
+		t.Errorf("class mode should fall back to inline once the palette exceeds classThreshold, got: %s", out)
+	}
+	if !strings.Contains(out, `fill="#`) {
+		t.Errorf("expected inline fill attributes after falling back, got: %s", out)
+	}
+}
+
+func TestOptimizeColorShorthand(t *testing.T) {
+	w := &SVGWriter{}
+	if got := w.optimizeColor(0xff, 0x00, 0x00); got != "#f00" {
+		t.Errorf("optimizeColor(ff,00,00) = %q, want shorthand #f00", got)
+	}
+	if got := w.optimizeColor(0x12, 0x34, 0x56); got != "#123456" {
+		t.Errorf("optimizeColor(12,34,56) = %q, want full #123456", got)
+	}
+}
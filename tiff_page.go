@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"os"
+)
+
+// loadTIFFPage decodes the page'th IFD (0-indexed) out of a multi-page TIFF.
+// tiff.Decode always reads the IFD pointed to by the header's first-IFD
+// offset, so to reach a later page we walk the IFD chain ourselves to find
+// its offset, patch that offset into a copy of the header, and hand the
+// patched bytes to the normal image.Decode path.
+func loadTIFFPage(path string, page int) (image.Image, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read TIFF file: %v", err)
+	}
+	if len(data) < 8 {
+		return nil, fmt.Errorf("not a valid TIFF file: %s", path)
+	}
+
+	var order binary.ByteOrder
+	switch string(data[0:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("not a valid TIFF file: %s", path)
+	}
+
+	ifdOffset := order.Uint32(data[4:8])
+	for i := 0; i < page; i++ {
+		next, err := nextTIFFIFDOffset(data, order, ifdOffset)
+		if err != nil {
+			return nil, fmt.Errorf("TIFF page %d out of range: %s has fewer pages: %v", page, path, err)
+		}
+		if next == 0 {
+			return nil, fmt.Errorf("TIFF page %d out of range: %s has fewer pages", page, path)
+		}
+		ifdOffset = next
+	}
+
+	patched := make([]byte, len(data))
+	copy(patched, data)
+	order.PutUint32(patched[4:8], ifdOffset)
+
+	img, _, err := image.Decode(bytes.NewReader(patched))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode TIFF page %d: %v", page, err)
+	}
+	return img, nil
+}
+
+// nextTIFFIFDOffset reads the IFD at ifdOffset and returns the offset of the
+// next IFD in the chain, or 0 if ifdOffset is the last page.
+func nextTIFFIFDOffset(data []byte, order binary.ByteOrder, ifdOffset uint32) (uint32, error) {
+	if int(ifdOffset)+2 > len(data) {
+		return 0, fmt.Errorf("truncated IFD")
+	}
+	entryCount := int(order.Uint16(data[ifdOffset : ifdOffset+2]))
+	nextOffsetPos := int(ifdOffset) + 2 + entryCount*12
+	if nextOffsetPos+4 > len(data) {
+		return 0, fmt.Errorf("truncated IFD")
+	}
+	return order.Uint32(data[nextOffsetPos : nextOffsetPos+4]), nil
+}
@@ -4,14 +4,20 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 )
 
-func generateOutputName(inputPath string) string {
+func autoGenerateOutputName(inputPath string) string {
 	ext := filepath.Ext(inputPath)
 	base := inputPath[:len(inputPath)-len(ext)]
 	return base + ".svg"
 }
 
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
 func getFileSize(path string) (int64, error) {
 	fileInfo, err := os.Stat(path)
 	if err != nil {
@@ -46,32 +52,16 @@ func calculateSizeReduction(inputSize, outputSize int64) float64 {
 	return float64(inputSize-outputSize) / float64(inputSize) * 100
 }
 
-func reportConversionResults(input, output string, inputSize int64, width, height, blocks int) error {
-	outputSize, err := getFileSize(output)
-	if err != nil {
-		return fmt.Errorf("get output size: %w", err)
-	}
-
-	reduction := calculateSizeReduction(inputSize, outputSize)
-
-	if !quiet {
-		printConversionSummary(input, output, inputSize, outputSize, reduction, width, height, blocks)
-	} else {
-		fmt.Printf("Converted: %s (%s) -> %s (%s) - %.1f%% reduction, %d blocks", 
-			filepath.Base(input), formatFileSize(inputSize),
-			filepath.Base(output), formatFileSize(outputSize),
-			reduction, blocks)
-	}
-
-	return nil
-}
-
-func printConversionSummary(input, output string, inputSize, outputSize int64, reduction float64, width, height, blocks int) {
+func printConversionSummary(input, output string, inputSize, outputSize int64, reduction float64, width, height, blocks, paletteSize, uniqueColors int, duration time.Duration) {
 	fmt.Printf("Conversion complete:\n")
 	fmt.Printf("  Input:  %s (%s)\n", filepath.Base(input), formatFileSize(inputSize))
 	fmt.Printf("  Output: %s (%s)\n", filepath.Base(output), formatFileSize(outputSize))
 	fmt.Printf("  Size reduction: %.1f%%\n", reduction)
 	fmt.Printf("  Dimensions: %dx%d pixels\n", width, height)
-	fmt.Printf("  Optimization: %d blocks (%.1fx compression)\n", 
+	fmt.Printf("  Optimization: %d blocks (%.1fx compression)\n",
 		blocks, float64(width*height)/float64(blocks))
+	if paletteSize > 0 {
+		fmt.Printf("  Palette: %d colors (%d used after remap)\n", paletteSize, uniqueColors)
+	}
+	fmt.Printf("  Time: %v\n", duration.Round(time.Millisecond))
 }
\ No newline at end of file
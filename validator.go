@@ -16,26 +16,7 @@ var supportedFormats = map[string]bool{
 	".bmp":  true,
 	".tiff": true,
 	".tif":  true,
-}
-
-func validateInputs(input, output string, width, height int) error {
-	if err := validateInputFile(input); err != nil {
-		return err
-	}
-
-	if output != "" {
-		if err := validateOutputFile(output, force); err != nil {
-			return err
-		}
-	}
-
-	if width > 0 || height > 0 {
-		if err := validateDimensions(width, height); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	".webp": true,
 }
 
 func validateInputFile(path string) error {
@@ -100,8 +81,8 @@ func validateOutputFile(path string, forceOverwrite bool) error {
 	os.Remove(testFile)
 
 	ext := strings.ToLower(filepath.Ext(path))
-	if ext != ".svg" {
-		return fmt.Errorf("output file must have .svg extension: %s", path)
+	if ext != ".svg" && ext != ".svgz" {
+		return fmt.Errorf("output file must have .svg or .svgz extension: %s", path)
 	}
 
 	return nil